@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
+	"go-version-switch/internal/config"
 	"go-version-switch/internal/version"
 )
 
@@ -18,14 +20,48 @@ type Command struct {
 }
 
 var (
-	listFlag     bool
-	updateFlag   bool
-	installFlag  string
-	useFlag      string
-	archFlag     string
-	rollbackFlag bool
-	helpFlag     bool
-	baseDir      string
+	listFlag      bool
+	updateFlag    bool
+	installFlag   string
+	useFlag       string
+	archFlag      string
+	osFlag        string
+	rollbackFlag  bool
+	helpFlag      bool
+	downloadFlag  string
+	dirFlag       string
+	progressFlag  string
+	skipVerify    bool
+	cacheDirFlag  string
+	urlFlag       string
+	sha256Flag    string
+	linkFlag      string
+	proxyFlag     string
+	h1Flag        string
+	sourceFlag    string
+	nameFlag      string
+	bootstrapFlag string
+	forceFlag     bool
+	uninstallFlag string
+	purgeFlag     bool
+	pruneFlag     bool
+	keepPatches   int
+	archiveDays   int
+	keepBackups   int
+	pruneArchives bool
+	dryRunFlag    bool
+	pinFlag       string
+	unpinFlag     string
+	duFlag        bool
+	verifyFlag    bool
+	repairFlag    bool
+	upgradeFlag   bool
+	removeOldFlag bool
+	importFlag    bool
+	moveFlag      bool
+	dataDirFlag   string
+	baseDir       string
+	releaseLock   func() // 释放数据目录锁，未持有时为 nil
 )
 
 // 定义所有支持的命令
@@ -50,11 +86,61 @@ var commands = []Command{
 		Description: "切换到指定的Go版本",
 		Example:     "go-version-switch -use 1.20.1",
 	},
+	{
+		Name:        "upgrade",
+		Description: "升级到次版本的最新补丁版本",
+		Example:     "go-version-switch -upgrade 1.21 -remove-old",
+	},
+	{
+		Name:        "uninstall",
+		Description: "卸载指定版本的Go",
+		Example:     "go-version-switch -uninstall 1.20.1 -arch x64",
+	},
+	{
+		Name:        "prune",
+		Description: "按保留策略清理旧版本、安装包和环境变量备份",
+		Example:     "go-version-switch -prune -keep-patches 2 -keep-backups 10 -dry-run",
+	},
+	{
+		Name:        "du",
+		Description: "统计数据目录的磁盘占用和可回收空间",
+		Example:     "go-version-switch -du",
+	},
+	{
+		Name:        "verify",
+		Description: "按安装清单校验已安装版本 (-repair 从安装包修复)",
+		Example:     "go-version-switch -verify 1.21.5 -repair",
+	},
+	{
+		Name:        "pin",
+		Description: "固定版本，清理时不会被删除 (-unpin 取消)",
+		Example:     "go-version-switch -pin 1.21.5",
+	},
 	{
 		Name:        "rollback",
 		Description: "回滚到上一次的环境变量配置",
 		Example:     "go-version-switch -rollback",
 	},
+	{
+		Name:        "install-source",
+		Description: "从源码编译Go并登记为可切换版本",
+		Example:     "go-version-switch -install-source D:\\src\\go -name tip",
+	},
+	{
+		Name:        "link",
+		Description: "登记已解压的Go目录，无需复制即可切换",
+		Example:     `go-version-switch -link corp-1.21 "C:\Program Files\Go"`,
+	},
+	{
+		Name:        "import",
+		Description: "导入 gvm、goenv、g、scoop、chocolatey 和 ~/sdk 中的Go (-move 移动到数据目录)",
+		Example:     "go-version-switch -import -dry-run",
+	},
+	{
+		Name:        "download",
+		Description: "仅下载并校验安装包（用于制作离线包）",
+		Example:     "go-version-switch -download 1.21.5,1.22.0 -arch x64,x86",
+	},
 	{
 		Name:        "help",
 		Description: "查看帮助信息",
@@ -63,21 +149,46 @@ var commands = []Command{
 }
 
 func init() {
-	// 获取可执行文件所在目录作为基础目录
-	execPath, err := os.Executable()
-	if err != nil {
-		fmt.Printf("获取程序路径失败: %v\n", err)
-		os.Exit(1)
-	}
-	baseDir = filepath.Join(filepath.Dir(execPath), "data")
-
 	// 解析命令行参数
+	flag.StringVar(&dataDirFlag, "data-dir", "", "指定数据目录 (默认: GVS_HOME 环境变量 > 程序目录下已有的 data > 系统数据目录)")
 	flag.BoolVar(&listFlag, "list", false, "列出所有可用的Go版本")
 	flag.BoolVar(&updateFlag, "update", false, "强制更新版本列表")
 	flag.StringVar(&installFlag, "install", "", "安装指定版本")
 	flag.StringVar(&useFlag, "use", "", "切换到指定版本")
+	flag.BoolVar(&upgradeFlag, "upgrade", false, "升级到次版本的最新补丁版本: -upgrade [1.21]，默认当前版本的次版本")
+	flag.BoolVar(&removeOldFlag, "remove-old", false, "升级后删除被替代的补丁版本")
+	flag.StringVar(&uninstallFlag, "uninstall", "", "卸载指定版本")
+	flag.BoolVar(&purgeFlag, "purge", false, "卸载时同时删除 data/down 和 data/cache 中的安装包")
+	flag.BoolVar(&pruneFlag, "prune", false, "按保留策略清理数据目录")
+	flag.IntVar(&keepPatches, "keep-patches", 0, "清理时每个次版本保留最新的 N 个补丁版本")
+	flag.BoolVar(&pruneArchives, "prune-archives", false, "清理时删除已安装版本的安装包")
+	flag.IntVar(&archiveDays, "archive-days", 0, "清理时删除超过 N 天的安装包")
+	flag.IntVar(&keepBackups, "keep-backups", 0, "清理时只保留最新的 N 个环境变量备份")
+	flag.BoolVar(&dryRunFlag, "dry-run", false, "只列出将被清理或导入的内容，不实际执行")
+	flag.BoolVar(&duFlag, "du", false, "统计数据目录的磁盘占用")
+	flag.BoolVar(&verifyFlag, "verify", false, "按安装清单校验已安装版本: -verify [版本]，不指定版本时校验全部")
+	flag.BoolVar(&repairFlag, "repair", false, "从安装包恢复被修改或缺失的文件 (隐含 -verify)")
+	flag.StringVar(&pinFlag, "pin", "", "固定版本，清理时不会被删除")
+	flag.StringVar(&unpinFlag, "unpin", "", "取消固定版本")
 	flag.StringVar(&archFlag, "arch", "", "指定架构 (x86/x64/arm/arm64)")
+	flag.StringVar(&osFlag, "os", "", "指定目标操作系统 (windows/linux/darwin)，默认当前系统")
 	flag.BoolVar(&rollbackFlag, "rollback", false, "回滚到上一次的环境变量配置")
+	flag.BoolVar(&importFlag, "import", false, "导入其他版本管理器安装的Go，默认只登记原目录")
+	flag.BoolVar(&moveFlag, "move", false, "导入时移动到 data/go-version (配合 -import 使用)")
+	flag.StringVar(&downloadFlag, "download", "", "仅下载指定版本的安装包，多个版本用逗号分隔")
+	flag.StringVar(&dirFlag, "dir", "", "指定安装包保存目录 (默认 data/down)")
+	flag.StringVar(&progressFlag, "progress", "auto", "进度输出模式 (auto/bar/plain/json/quiet)")
+	flag.BoolVar(&skipVerify, "skip-verify", false, "跳过本地安装包的校验和检查 (不推荐)")
+	flag.StringVar(&urlFlag, "url", "", "从自定义地址下载安装包 (配合 -install 使用)")
+	flag.StringVar(&sha256Flag, "sha256", "", "自定义安装包的 SHA256 校验和")
+	flag.StringVar(&proxyFlag, "proxy", "", "从模块代理安装 golang.org/toolchain 工具链 (env 表示使用 GOPROXY)")
+	flag.StringVar(&h1Flag, "h1", "", "工具链模块的 go.sum 哈希 (h1:...)，默认从代理的校验和数据库查询并校验签名")
+	flag.StringVar(&sourceFlag, "install-source", "", "从源码目录或源码包编译安装Go")
+	flag.StringVar(&nameFlag, "name", "", "源码编译版本的名称 (配合 -install-source 使用)")
+	flag.StringVar(&bootstrapFlag, "bootstrap", "", "源码编译使用的自举工具链：已安装的版本号或GOROOT路径")
+	flag.BoolVar(&forceFlag, "force", false, "覆盖同名的已有版本，允许使用版本号作为名称 (配合 -install-source 使用)")
+	flag.StringVar(&linkFlag, "link", "", "登记已有的GOROOT目录: -link <名称> <路径>")
+	flag.StringVar(&cacheDirFlag, "cache-dir", "", "指定共享下载缓存目录 (默认读取配置 cache_dir 或 data/cache)")
 }
 
 // printHelp 打印格式化的帮助信息
@@ -104,6 +215,37 @@ func printHelp() {
 	fmt.Println("                  • x64, amd64, x86-64 (64位)")
 	fmt.Println("                  • arm                (ARM)")
 	fmt.Println("                  • arm64              (ARM64)")
+	fmt.Println("                  -download 时可用逗号指定多个架构")
+	fmt.Println("  -os string      指定目标操作系统，默认当前系统:")
+	fmt.Println("                  • windows            (zip 安装包)")
+	fmt.Println("                  • linux, darwin      (tar.gz 安装包)")
+	fmt.Println("  -dir string     -download 的保存目录 (默认 data/down)")
+	fmt.Println("  -progress string 进度输出模式:")
+	fmt.Println("                  • auto   根据终端自动选择 (默认)")
+	fmt.Println("                  • bar    终端进度条")
+	fmt.Println("                  • plain  逐行输出，适合 CI 日志")
+	fmt.Println("                  • json   JSON 事件 (输出到 stderr)")
+	fmt.Println("                  • quiet  不显示进度")
+	fmt.Println("  -skip-verify    安装本地包时跳过校验和检查 (仅在确认来源可信时使用)")
+	fmt.Println("  -cache-dir string 共享下载缓存目录，按 SHA256 存储，可放在网络共享上")
+	fmt.Println("  -url string     -install 时从自定义地址下载安装包 (zip 或 tar.gz)")
+	fmt.Println("  -sha256 string  自定义安装包的 SHA256 校验和 (使用 -url 时必填)")
+	fmt.Println("  -proxy string   -install 时从模块代理下载工具链模块 (Go 1.21+):")
+	fmt.Println("                  • https://goproxy.corp   (Athens 等 GOPROXY 服务)")
+	fmt.Println("                  • file:///D:/goproxy     (本地目录形式的代理)")
+	fmt.Println("                  • env                    (使用 GOPROXY 环境变量)")
+	fmt.Println("  -h1 string      工具链模块的 go.sum 哈希，默认从代理的 sumdb 接口查询并校验签名")
+	fmt.Println("  -purge          -uninstall 时同时删除 data/down 和 data/cache 中的安装包")
+	fmt.Println("  -prune 清理策略 (可组合，未指定的策略不启用):")
+	fmt.Println("                  • -keep-patches N    每个次版本保留最新 N 个补丁版本")
+	fmt.Println("                  • -prune-archives    删除已安装版本的安装包")
+	fmt.Println("                  • -archive-days N    删除超过 N 天的安装包")
+	fmt.Println("                  • -keep-backups N    只保留最新 N 个环境变量备份")
+	fmt.Println("                  • -dry-run           只列出将被清理的内容")
+	fmt.Println("                  中断安装的残留目录总会被清理，当前版本和固定版本不会被删除")
+	fmt.Println("  -name string    -install-source 编译结果的版本名称 (如 tip)")
+	fmt.Println("  -bootstrap string 源码编译的自举工具链，默认使用已安装的最新版本")
+	fmt.Println("  -force          -install-source 覆盖同名的已有版本，允许使用版本号作为名称")
 
 	fmt.Println("\n📝 使用示例:")
 	fmt.Println("  1. 列出可用版本:")
@@ -115,25 +257,68 @@ func printHelp() {
 	fmt.Println("\n  3. 切换到指定版本:")
 	fmt.Printf("     %s -use 1.20.1\n", filepath.Base(os.Args[0]))
 
-	fmt.Println("\n  4. 直接切换架构:")
+	fmt.Println("\n  4. 升级到最新补丁版本 (-remove-old 删除旧补丁):")
+	fmt.Printf("     %s -upgrade\n", filepath.Base(os.Args[0]))
+	fmt.Printf("     %s -upgrade 1.21 -arch x64 -remove-old\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  5. 卸载指定版本 (-purge 同时删除安装包):")
+	fmt.Printf("     %s -uninstall 1.20.1 -arch x64 -purge\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  6. 清理旧版本和安装包 (先预演):")
+	fmt.Printf("     %s -prune -keep-patches 1 -prune-archives -keep-backups 10 -dry-run\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  7. 校验并修复安装目录:")
+	fmt.Printf("     %s -verify\n", filepath.Base(os.Args[0]))
+	fmt.Printf("     %s -verify 1.21.5 -arch x64 -repair\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  8. 直接切换架构:")
 	fmt.Printf("     %s -arch x64\n", filepath.Base(os.Args[0]))
 	fmt.Printf("     %s -arch x86\n", filepath.Base(os.Args[0]))
 
-	fmt.Println("\n  5. 回滚环境变量:")
+	fmt.Println("\n  9. 回滚环境变量:")
 	fmt.Printf("     %s -rollback\n", filepath.Base(os.Args[0]))
 
-	fmt.Println("\n  6. 强制更新版本列表:")
+	fmt.Println("\n  10. 强制更新版本列表:")
 	fmt.Printf("     %s -list -update\n", filepath.Base(os.Args[0]))
 
+	fmt.Println("\n  11. 从自定义地址安装:")
+	fmt.Printf("     %s -install 1.21.5 -arch x64 -url https://artifactory.corp/go1.21.5-custom.zip -sha256 <校验和>\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  12. 从模块代理安装:")
+	fmt.Printf("     %s -install 1.21.5 -arch x64 -proxy https://goproxy.corp\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  13. 登记已有的Go目录:")
+	fmt.Printf("     %s -link corp-1.21 \"C:\\Program Files\\Go\"\n", filepath.Base(os.Args[0]))
+	fmt.Printf("     %s -use corp-1.21\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  14. 导入其他版本管理器安装的Go (-move 移动到数据目录):")
+	fmt.Printf("     %s -import -dry-run\n", filepath.Base(os.Args[0]))
+	fmt.Printf("     %s -import -move\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  15. 下载离线安装包:")
+	fmt.Printf("     %s -download 1.21.5,1.22.0 -arch x64,x86\n", filepath.Base(os.Args[0]))
+
+	fmt.Println("\n  16. 从源码编译 Go tip:")
+	fmt.Printf("     %s -install-source D:\\src\\go -name tip -bootstrap 1.22.0\n", filepath.Base(os.Args[0]))
+	fmt.Printf("     %s -use tip\n", filepath.Base(os.Args[0]))
+
 	fmt.Println("\n📌 注意事项:")
 	fmt.Println("  • 修改系统环境变量需要管理员权限")
 	fmt.Println("  • 切换版本后需要重启终端和编辑器")
 	fmt.Println("  • 如果安装失败，可以使用 -rollback 回滚")
 	fmt.Println("  • 支持自动检测和使用本地安装包")
+	fmt.Println("  • 本地安装包会按版本索引校验 SHA256，未知或不匹配的包将被拒绝")
+	fmt.Println("  • 修改数据目录的命令同一时间只能运行一个，被占用时会提示持有锁的进程")
 
 	fmt.Println("\n💡 目录说明:")
+	fmt.Println("  数据目录按以下顺序确定: -data-dir 参数 > GVS_HOME 环境变量 > 程序目录下已有的 data 目录 >")
+	fmt.Println("  系统数据目录 (Windows: %LOCALAPPDATA%\\go-version-switch，Linux: ~/.local/share/go-version-switch)")
 	fmt.Println("  • go-version/: Go版本安装目录")
 	fmt.Println("  • down/: 安装包下载目录")
+	fmt.Println("  • cache/: 按 SHA256 存储的共享下载缓存 (可通过 -cache-dir 或配置 cache_dir 修改)")
+	fmt.Println("  • tools/: 各版本通过 go install 安装的工具 (配置 tools 列表)")
+	fmt.Println("  • bin/: 当前版本的工具，切换版本时自动更新")
+	fmt.Println("  • manifest/: 安装时记录的文件清单 (用于 -verify)")
 	fmt.Println("  • backup_env/: 环境变量备份目录")
 	fmt.Println("  • config/: 配置文件目录")
 
@@ -174,6 +359,24 @@ func findSimilarCommand(input string) string {
 	return ""
 }
 
+// exit 释放数据目录锁后退出
+func exit(code int) {
+	if releaseLock != nil {
+		releaseLock()
+	}
+	os.Exit(code)
+}
+
+// modifiesDataDir 判断命令是否会修改数据目录
+func modifiesDataDir() bool {
+	if dryRunFlag && (pruneFlag || importFlag) {
+		return false
+	}
+	return installFlag != "" || useFlag != "" || rollbackFlag || pruneFlag || uninstallFlag != "" ||
+		upgradeFlag || importFlag || linkFlag != "" || sourceFlag != "" || pinFlag != "" || unpinFlag != "" ||
+		repairFlag || downloadFlag != "" || updateFlag
+}
+
 // printRefreshTips 打印环境变量刷新提示
 func printRefreshTips() {
 	fmt.Println("\n💡 如果终端环境变量未更新，请尝试以下方法手动刷新:")
@@ -188,8 +391,31 @@ func printRefreshTips() {
 func main() {
 	flag.Parse()
 
+	// 确定数据目录，所有包共用同一个目录
+	dir, err := config.ResolveDataDir(dataDirFlag)
+	if err != nil {
+		fmt.Printf("确定数据目录失败: %v\n", err)
+		os.Exit(1)
+	}
+	baseDir = dir
+	config.SetDataDir(baseDir)
+
+	// -link 的路径参数位于普通参数中
+	var linkPath string
+	args := flag.Args()
+	if linkFlag != "" && len(args) > 0 {
+		linkPath, args = args[0], args[1:]
+	}
+	// -verify 和 -upgrade 的版本参数位于普通参数中，其后的参数（如 -arch）需要继续解析
+	var versionArg string
+	if (verifyFlag || repairFlag || upgradeFlag) && len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		versionArg = args[0]
+		flag.CommandLine.Parse(args[1:])
+		args = flag.Args()
+	}
+
 	// 检查未识别的参数
-	for _, arg := range flag.Args() {
+	for _, arg := range args {
 		if strings.HasPrefix(arg, "-") {
 			if similar := findSimilarCommand(arg); similar != "" {
 				fmt.Printf("未知参数: %s\n你是否想要使用 -%s?\n", arg, similar)
@@ -206,6 +432,14 @@ func main() {
 		}
 	}
 
+	// 设置进度输出模式
+	if err := version.SetProgressMode(progressFlag); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	version.SetArchiveCacheDir(cacheDirFlag)
+
 	// 创建基础目录
 	if err := os.MkdirAll(baseDir, 0755); err != nil {
 		fmt.Printf("创建数据目录失败: %v\n", err)
@@ -218,12 +452,26 @@ func main() {
 		return
 	}
 
+	// 只指定 -arch 时切换架构
+	archSwitch := archFlag != "" && !listFlag && !updateFlag &&
+		installFlag == "" && useFlag == "" && !rollbackFlag && downloadFlag == "" && linkFlag == "" && sourceFlag == "" && uninstallFlag == "" && !pruneFlag && !duFlag && !verifyFlag && !repairFlag && !upgradeFlag && !importFlag
+
+	// 修改数据目录的命令需要持有数据目录锁，避免多个进程同时安装或写入配置
+	if archSwitch || modifiesDataDir() {
+		release, err := version.LockDataDir(baseDir, strings.Join(os.Args[1:], " "))
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		releaseLock = release
+		defer release()
+	}
+
 	// 处理架构切换
-	if archFlag != "" && !listFlag && !updateFlag &&
-		installFlag == "" && useFlag == "" && !rollbackFlag {
-		if err := version.HandleArchitectureSwitch(baseDir, archFlag); err != nil {
+	if archSwitch {
+		if err := version.HandleArchitectureSwitch(baseDir, archFlag, skipVerify); err != nil {
 			fmt.Printf("切换架构失败: %v\n", err)
-			os.Exit(1)
+			exit(1)
 		}
 		printRefreshTips()
 		return
@@ -240,7 +488,7 @@ func main() {
 	if rollbackFlag {
 		if err := handleRollback(); err != nil {
 			fmt.Printf("回滚失败: %v\n", err)
-			os.Exit(1)
+			exit(1)
 		}
 		return
 	}
@@ -251,43 +499,216 @@ func main() {
 		if err != nil {
 			fmt.Printf("获取版本列表失败: ")
 			fmt.Println(err)
-			os.Exit(1)
+			exit(1)
 		}
 		list.PrintVersionList()
 		return
 	}
 
+	// 处理登记目录命令
+	if linkFlag != "" {
+		if linkPath == "" {
+			fmt.Println("缺少目录参数，用法: go-version-switch -link <名称> <路径>")
+			exit(1)
+		}
+		if err := version.LinkVersion(baseDir, linkFlag, linkPath); err != nil {
+			fmt.Printf("登记失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理导入命令
+	if importFlag {
+		opts := version.ImportOptions{
+			Move:   moveFlag,
+			DryRun: dryRunFlag,
+		}
+		if err := version.ImportVersions(baseDir, opts); err != nil {
+			fmt.Printf("导入失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理预下载命令
+	if downloadFlag != "" {
+		opts := version.PrefetchOptions{
+			Versions: splitList(downloadFlag),
+			Archs:    splitList(archFlag),
+			OS:       osFlag,
+			Dir:      dirFlag,
+		}
+		if len(opts.Archs) == 0 {
+			opts.Archs = []string{runtime.GOARCH}
+		}
+		if err := version.PrefetchVersions(baseDir, opts); err != nil {
+			fmt.Printf("下载失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理源码编译命令
+	if sourceFlag != "" {
+		opts := version.SourceBuildOptions{
+			Source:    sourceFlag,
+			Name:      nameFlag,
+			Bootstrap: bootstrapFlag,
+			Force:     forceFlag,
+		}
+		if err := version.InstallFromSource(baseDir, opts); err != nil {
+			fmt.Printf("编译安装失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
 	// 处理安装命令
 	if installFlag != "" {
 		opts := version.InstallOptions{
-			Version: installFlag,
-			Arch:    archFlag,
+			Version:    installFlag,
+			OS:         osFlag,
+			Arch:       archFlag,
+			URL:        urlFlag,
+			SHA256:     sha256Flag,
+			Proxy:      proxyFlag,
+			H1:         h1Flag,
+			SkipVerify: skipVerify,
 		}
 		if err := version.InstallVersion(baseDir, opts); err != nil {
 			fmt.Printf("安装失败: ")
 			fmt.Println(err)
-			os.Exit(1)
+			exit(1)
+		}
+		printRefreshTips()
+		return
+	}
+
+	// 处理升级命令
+	if upgradeFlag {
+		opts := version.UpgradeOptions{
+			Minor:     versionArg,
+			OS:        osFlag,
+			Arch:      archFlag,
+			RemoveOld: removeOldFlag,
+		}
+		if err := version.UpgradeVersion(baseDir, opts); err != nil {
+			fmt.Printf("升级失败: ")
+			fmt.Println(err)
+			exit(1)
 		}
 		printRefreshTips()
 		return
 	}
 
+	// 处理卸载命令
+	if uninstallFlag != "" {
+		opts := version.UninstallOptions{
+			Version: uninstallFlag,
+			OS:      osFlag,
+			Arch:    archFlag,
+			Purge:   purgeFlag,
+		}
+		if err := version.UninstallVersion(baseDir, opts); err != nil {
+			fmt.Printf("卸载失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理清理命令
+	if pruneFlag {
+		opts := version.PruneOptions{
+			KeepPatches:       keepPatches,
+			InstalledArchives: pruneArchives,
+			ArchiveDays:       archiveDays,
+			KeepBackups:       keepBackups,
+			DryRun:            dryRunFlag,
+		}
+		if err := version.Prune(baseDir, opts); err != nil {
+			fmt.Printf("清理失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理磁盘占用统计命令
+	if duFlag {
+		if err := version.DiskUsage(baseDir); err != nil {
+			fmt.Printf("统计失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理校验命令
+	if verifyFlag || repairFlag {
+		opts := version.VerifyOptions{
+			Version: versionArg,
+			OS:      osFlag,
+			Arch:    archFlag,
+			Repair:  repairFlag,
+		}
+		if err := version.VerifyVersions(baseDir, opts); err != nil {
+			fmt.Printf("校验失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
+	// 处理固定版本命令
+	if pinFlag != "" || unpinFlag != "" {
+		var err error
+		if pinFlag != "" {
+			err = version.SetPinned(pinFlag, true)
+		} else {
+			err = version.SetPinned(unpinFlag, false)
+		}
+		if err != nil {
+			fmt.Printf("设置失败: ")
+			fmt.Println(err)
+			exit(1)
+		}
+		return
+	}
+
 	// 处理切换版本命令
 	if useFlag != "" {
 		opts := version.InstallOptions{
 			Version: useFlag,
+			OS:      osFlag,
 			Arch:    archFlag,
 		}
 		if err := version.UseVersion(baseDir, opts); err != nil {
 			fmt.Printf("切换版本失败: ")
 			fmt.Println(err)
-			os.Exit(1)
+			exit(1)
 		}
 		printRefreshTips()
 		return
 	}
 }
 
+// splitList 解析逗号分隔的参数列表
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // handleRollback 处理环境变量回滚
 func handleRollback() error {
 	// 检查管理员权限
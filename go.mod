@@ -1,3 +1,5 @@
 module go-version-switch
 
 go 1.20
+
+require golang.org/x/mod v0.17.0
@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,10 +12,14 @@ import (
 
 // Config 表示工具的配置信息
 type Config struct {
+	SchemaVersion  int               `json:"schema_version"`  // 配置文件格式版本
 	BaseDir        string            `json:"base_dir"`        // Go版本安装的基础目录
 	CurrentVersion string            `json:"current_version"` // 当前使用的Go版本
 	Versions       map[string]string `json:"versions"`        // 已安装的版本映射 version -> path
 	LastUpdate     CustomTime        `json:"last_update"`     // 上次更新时间
+	CacheDir       string            `json:"cache_dir"`       // 共享下载缓存目录，为空时使用 data/cache
+	Tools          []string          `json:"tools"`           // 每个版本自动安装的工具，如 golang.org/x/tools/gopls@v0.16.2
+	Pinned         []string          `json:"pinned"`          // 固定的版本，清理时不会被删除
 }
 
 // CustomTime 自定义时间类型，用于格式化 JSON 输出
@@ -44,17 +49,13 @@ func (ct *CustomTime) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-var (
-	// 获取程序当前目录
-	execDir, _        = os.Executable()
-	dataDir           = filepath.Join(filepath.Dir(execDir), "data")
-	defaultConfigPath = filepath.Join(dataDir, "config", "config.json")
-)
+// backupSuffix 配置备份文件的后缀，保存的是上一次写入前的有效配置
+const backupSuffix = ".bak"
 
 // LoadConfig 加载配置文件
 func LoadConfig() (*Config, error) {
 	// 确保配置目录存在
-	configDir := filepath.Join(filepath.Dir(os.Args[0]), "data", "config")
+	configDir := filepath.Join(DataDir(), "config")
 	if err := os.MkdirAll(configDir, 0755); err != nil {
 		return nil, fmt.Errorf("创建配置目录失败: %v", err)
 	}
@@ -66,43 +67,131 @@ func LoadConfig() (*Config, error) {
 			// 如果配置文件不存在，创建默认配置
 			defaultTime := time.Date(2024, 1, 1, 23, 59, 59, 0, time.Local)
 			config := &Config{
-				BaseDir:    filepath.Join(filepath.Dir(os.Args[0]), "data", "go-version"),
-				Versions:   make(map[string]string),
-				LastUpdate: CustomTime{Time: defaultTime},
+				SchemaVersion: len(configMigrations),
+				BaseDir:       filepath.Join(DataDir(), "go-version"),
+				Versions:      make(map[string]string),
+				LastUpdate:    CustomTime{Time: defaultTime},
 			}
 			return config, SaveConfig(config)
 		}
 		return nil, fmt.Errorf("读取配置文件失败: %v", err)
 	}
 
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
+	config, migrated, err := parseConfig(data)
+	if errors.Is(err, errNewerSchema) {
 		return nil, fmt.Errorf("解析配置文件失败: %v", err)
 	}
+	if err != nil {
+		// 配置文件损坏时使用上一次保存的备份
+		backupFile := configFile + backupSuffix
+		backupData, berr := os.ReadFile(backupFile)
+		if berr != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %v (没有可用的备份)", err)
+		}
+		backup, _, berr := parseConfig(backupData)
+		if berr != nil {
+			return nil, fmt.Errorf("解析配置文件失败: %v (备份也已损坏: %v)", err, berr)
+		}
+		fmt.Printf("⚠️ 配置文件已损坏 (%v)，已使用备份: %s\n", err, backupFile)
+
+		// 保留损坏的文件以便排查，并用备份恢复配置文件
+		corruptFile := configFile + ".corrupt"
+		if err := os.Rename(configFile, corruptFile); err == nil {
+			fmt.Printf("💡 损坏的配置文件已保存为: %s\n", corruptFile)
+		}
+		if err := writeFileAtomic(configFile, backupData, 0644); err != nil {
+			fmt.Printf("警告: 恢复配置文件失败: %v\n", err)
+		}
+		return backup, nil
+	}
+
+	// 旧格式的配置升级后写回，原文件保留为备份
+	if migrated {
+		fmt.Printf("🔄 配置文件已升级到格式版本 %d\n", config.SchemaVersion)
+		if err := SaveConfig(config); err != nil {
+			fmt.Printf("警告: 保存升级后的配置失败: %v\n", err)
+		}
+	}
+
+	return config, nil
+}
+
+// parseConfig 解析配置内容，旧格式会先升级到最新格式
+func parseConfig(data []byte) (*Config, bool, error) {
+	var config Config
+	migrated, err := DecodeMigrated(data, configMigrations, &config)
+	if err != nil {
+		return nil, false, err
+	}
 
 	// 确保版本映射已初始化
 	if config.Versions == nil {
 		config.Versions = make(map[string]string)
 	}
 
-	return &config, nil
+	return &config, migrated, nil
 }
 
 // SaveConfig 保存配置到文件
 func SaveConfig(config *Config) error {
-	configFile := filepath.Join(filepath.Dir(os.Args[0]), "data", "config", "config.json")
+	configFile := filepath.Join(DataDir(), "config", "config.json")
+	config.SchemaVersion = len(configMigrations)
 	data, err := json.MarshalIndent(config, "", "    ")
 	if err != nil {
 		return fmt.Errorf("序列化配置失败: %v", err)
 	}
 
-	if err := os.WriteFile(configFile, data, 0644); err != nil {
+	// 保留上一份有效的配置作为备份
+	if old, err := os.ReadFile(configFile); err == nil {
+		if _, _, err := parseConfig(old); err == nil {
+			if err := writeFileAtomic(configFile+backupSuffix, old, 0644); err != nil {
+				fmt.Printf("警告: 备份配置文件失败: %v\n", err)
+			}
+		}
+	}
+
+	if err := writeFileAtomic(configFile, data, 0644); err != nil {
 		return fmt.Errorf("保存配置文件失败: %v", err)
 	}
 
 	return nil
 }
 
+// writeFileAtomic 先写入同目录的临时文件并同步到磁盘，再重命名替换目标文件，
+// 写入过程中崩溃不会留下不完整的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpName, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpName, path)
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	// 同步目录项，确保重命名在断电后依然有效（Windows 不支持，忽略错误）
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
 // AddVersion 添加新版本到配置
 func (c *Config) AddVersion(version, path string) error {
 	c.Versions[version] = path
@@ -115,6 +204,36 @@ func (c *Config) RemoveVersion(version string) error {
 	return SaveConfig(c)
 }
 
+// IsPinned 判断版本是否已固定
+func (c *Config) IsPinned(version string) bool {
+	for _, v := range c.Pinned {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Pin 固定版本，清理时保留
+func (c *Config) Pin(version string) error {
+	if !c.IsPinned(version) {
+		c.Pinned = append(c.Pinned, version)
+	}
+	return SaveConfig(c)
+}
+
+// Unpin 取消固定版本
+func (c *Config) Unpin(version string) error {
+	pinned := c.Pinned[:0]
+	for _, v := range c.Pinned {
+		if v != version {
+			pinned = append(pinned, v)
+		}
+	}
+	c.Pinned = pinned
+	return SaveConfig(c)
+}
+
 // SetCurrentVersion 设置当前使用的版本
 func (c *Config) SetCurrentVersion(version string) error {
 	if _, exists := c.Versions[version]; !exists {
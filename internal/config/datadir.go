@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+const (
+	// DataDirEnv 指定数据目录的环境变量
+	DataDirEnv = "GVS_HOME"
+	appName    = "go-version-switch"
+)
+
+// dataDir 当前使用的数据目录，由 SetDataDir 设置
+var dataDir string
+
+// ResolveDataDir 按优先级确定数据目录:
+// -data-dir 参数 > GVS_HOME 环境变量 > 程序所在目录下已存在的 data 目录（旧版本位置）> 系统默认位置
+func ResolveDataDir(flagDir string) (string, error) {
+	if flagDir != "" {
+		return filepath.Abs(flagDir)
+	}
+	if dir := os.Getenv(DataDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+	if dir := legacyDataDir(); dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return defaultDataDir()
+}
+
+// legacyDataDir 返回旧版本使用的程序所在目录下的 data 目录，通过符号链接运行时使用实际路径
+func legacyDataDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Join(filepath.Dir(exe), "data")
+}
+
+// defaultDataDir 返回系统默认的数据目录:
+// Windows 为 %LOCALAPPDATA%，macOS 为 ~/Library/Application Support，其他系统为 $XDG_DATA_HOME 或 ~/.local/share
+func defaultDataDir() (string, error) {
+	switch runtime.GOOS {
+	case "windows":
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, appName), nil
+		}
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("无法确定数据目录，请使用 -data-dir 或 %s 指定: %v", DataDirEnv, err)
+		}
+		return filepath.Join(dir, appName), nil
+	case "darwin":
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("无法确定数据目录，请使用 -data-dir 或 %s 指定: %v", DataDirEnv, err)
+		}
+		return filepath.Join(dir, appName), nil
+	}
+
+	if dir := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("无法确定数据目录，请使用 -data-dir 或 %s 指定: %v", DataDirEnv, err)
+	}
+	return filepath.Join(home, ".local", "share", appName), nil
+}
+
+// SetDataDir 设置数据目录，所有配置、安装目录和备份都位于其下
+func SetDataDir(dir string) {
+	dataDir = dir
+}
+
+// DataDir 返回数据目录，未设置时按默认规则确定
+func DataDir() string {
+	if dataDir == "" {
+		if dir, err := ResolveDataDir(""); err == nil {
+			dataDir = dir
+		} else {
+			dataDir = legacyDataDir()
+		}
+	}
+	return dataDir
+}
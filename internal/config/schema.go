@@ -0,0 +1,100 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SchemaKey 数据文件中记录格式版本的字段
+const SchemaKey = "schema_version"
+
+// errNewerSchema 文件由更新版本的工具写入，无法安全读取
+var errNewerSchema = errors.New("文件格式版本高于当前支持的版本，请升级 go-version-switch")
+
+// Migration 将数据从某个格式版本升级到下一个版本，doc 为 JSON 解码后的原始数据
+type Migration func(doc any) (any, error)
+
+// Migrate 依次执行 doc 当前格式版本之后的迁移，返回升级后的数据以及是否发生了迁移。
+// 最新格式版本等于迁移的数量，没有版本字段的旧文件视为版本 0
+func Migrate(doc any, migrations []Migration) (any, bool, error) {
+	latest := len(migrations)
+	version, err := schemaVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > latest {
+		return nil, false, fmt.Errorf("%w (文件: %d，支持: %d)", errNewerSchema, version, latest)
+	}
+	if version == latest {
+		return doc, false, nil
+	}
+
+	for v := version; v < latest; v++ {
+		if doc, err = migrations[v](doc); err != nil {
+			return nil, false, fmt.Errorf("从格式版本 %d 迁移失败: %v", v, err)
+		}
+	}
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return nil, false, fmt.Errorf("迁移后的数据不是 JSON 对象")
+	}
+	obj[SchemaKey] = latest
+	return obj, true, nil
+}
+
+// DecodeMigrated 解码 JSON 并升级到最新格式后写入 v，返回是否发生了迁移
+func DecodeMigrated(data []byte, migrations []Migration, v any) (bool, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false, err
+	}
+	doc, migrated, err := Migrate(doc, migrations)
+	if err != nil {
+		return false, err
+	}
+	if migrated {
+		if data, err = json.Marshal(doc); err != nil {
+			return false, err
+		}
+	}
+	return migrated, json.Unmarshal(data, v)
+}
+
+// schemaVersion 读取格式版本，旧格式可能不是 JSON 对象（如版本缓存的数组）
+func schemaVersion(doc any) (int, error) {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return 0, nil
+	}
+	raw, ok := obj[SchemaKey]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := raw.(float64)
+	if !ok || n < 0 || n != float64(int(n)) {
+		return 0, fmt.Errorf("无效的格式版本: %v", raw)
+	}
+	return int(n), nil
+}
+
+// configMigrations 配置文件的迁移，下标为迁移前的格式版本
+var configMigrations = []Migration{
+	// 版本 0 -> 1: last_update 统一为 "2006-01-02 15:04:05" 格式，补全为 null 的版本映射
+	func(doc any) (any, error) {
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("配置不是 JSON 对象")
+		}
+		if s, ok := obj["last_update"].(string); ok {
+			if t, err := time.Parse(time.RFC3339, s); err == nil {
+				obj["last_update"] = t.Local().Format("2006-01-02 15:04:05")
+			}
+		}
+		if _, ok := obj["versions"].(map[string]any); !ok {
+			obj["versions"] = map[string]any{}
+		}
+		return obj, nil
+	},
+}
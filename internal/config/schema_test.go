@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// readFixture 读取 testdata 中的历史格式文件
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	return readFile(t, filepath.Join("testdata", name))
+}
+
+// localTime 迁移后的 last_update 按本地时间记录，读取时与 CustomTime 一样按 UTC 解析
+func localTime(t *testing.T, rfc3339 string) string {
+	t.Helper()
+	ts, err := time.Parse(time.RFC3339, rfc3339)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts.Local().Format("2006-01-02 15:04:05")
+}
+
+var configFixtures = []struct {
+	name       string
+	fixture    string
+	migrated   bool
+	lastUpdate string // 期望的 last_update，格式 2006-01-02 15:04:05
+	versions   int
+	newer      bool // 格式版本高于当前支持的版本，应被拒绝
+}{
+	{name: "v0 RFC3339 时间且 versions 为 null", fixture: "config_v0.json", migrated: true, lastUpdate: "2024-03-01T10:20:30+08:00"},
+	{name: "v0 已安装版本", fixture: "config_v0_versions.json", migrated: true, lastUpdate: "2024-01-01 23:59:59", versions: 2},
+	{name: "未来格式版本", fixture: "config_future.json", newer: true},
+}
+
+func (c *Config) checkFixture(t *testing.T, lastUpdate string, versions int) {
+	t.Helper()
+
+	if c.SchemaVersion != len(configMigrations) {
+		t.Errorf("SchemaVersion = %d，期望 %d", c.SchemaVersion, len(configMigrations))
+	}
+	if c.Versions == nil {
+		t.Error("Versions 不应为 nil")
+	}
+	if len(c.Versions) != versions {
+		t.Errorf("len(Versions) = %d，期望 %d", len(c.Versions), versions)
+	}
+	if c.CurrentVersion != "1.21.5" {
+		t.Errorf("CurrentVersion = %q", c.CurrentVersion)
+	}
+	want := lastUpdate
+	if _, err := time.Parse(time.RFC3339, lastUpdate); err == nil {
+		want = localTime(t, lastUpdate)
+	}
+	if got := c.LastUpdate.Format("2006-01-02 15:04:05"); got != want {
+		t.Errorf("LastUpdate = %s，期望 %s", got, want)
+	}
+}
+
+func TestDecodeMigratedConfig(t *testing.T) {
+	for _, tt := range configFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Config
+			migrated, err := DecodeMigrated(readFixture(t, tt.fixture), configMigrations, &cfg)
+			if tt.newer {
+				if !errors.Is(err, errNewerSchema) {
+					t.Fatalf("DecodeMigrated = %v，期望 errNewerSchema", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeMigrated: %v", err)
+			}
+			if migrated != tt.migrated {
+				t.Errorf("migrated = %v，期望 %v", migrated, tt.migrated)
+			}
+			cfg.checkFixture(t, tt.lastUpdate, tt.versions)
+		})
+	}
+}
+
+func TestDecodeMigratedCurrentSchema(t *testing.T) {
+	data := []byte(`{"schema_version": 1, "versions": {}, "last_update": "2024-01-01 23:59:59"}`)
+	var cfg Config
+	migrated, err := DecodeMigrated(data, configMigrations, &cfg)
+	if err != nil || migrated {
+		t.Fatalf("DecodeMigrated = %v, %v；当前格式不应迁移", migrated, err)
+	}
+}
+
+func TestLoadConfigFixtures(t *testing.T) {
+	old := DataDir()
+	t.Cleanup(func() { SetDataDir(old) })
+
+	for _, tt := range configFixtures {
+		t.Run(tt.name, func(t *testing.T) {
+			SetDataDir(t.TempDir())
+			configFile := filepath.Join(DataDir(), "config", "config.json")
+			original := readFixture(t, tt.fixture)
+			if err := os.MkdirAll(filepath.Dir(configFile), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(configFile, original, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.newer {
+				// 有可用的备份时也不能回退到旧配置
+				if err := os.WriteFile(configFile+backupSuffix, readFixture(t, "config_v0_versions.json"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := LoadConfig()
+			if tt.newer {
+				if err == nil {
+					t.Fatal("LoadConfig 应拒绝更高的格式版本")
+				}
+				if data, _ := os.ReadFile(configFile); string(data) != string(original) {
+					t.Error("更高格式版本的配置文件被修改")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			cfg.checkFixture(t, tt.lastUpdate, tt.versions)
+
+			// 升级后的配置已写回
+			var saved map[string]any
+			if err := json.Unmarshal(readFile(t, configFile), &saved); err != nil {
+				t.Fatal(err)
+			}
+			if saved[SchemaKey] != float64(len(configMigrations)) {
+				t.Errorf("写回的 schema_version = %v", saved[SchemaKey])
+			}
+			if _, ok := saved["versions"].(map[string]any); !ok {
+				t.Errorf("写回的 versions = %v，期望对象", saved["versions"])
+			}
+		})
+	}
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,191 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go-version-switch/internal/config"
+)
+
+const cacheLockTimeout = 10 * time.Minute
+
+// archiveCacheDir 命令行指定的共享缓存目录，优先于配置文件
+var archiveCacheDir string
+
+// SetArchiveCacheDir 设置共享下载缓存目录
+func SetArchiveCacheDir(dir string) {
+	archiveCacheDir = dir
+}
+
+// ArchiveCache 按 SHA256 存储安装包的共享缓存，可放在网络共享目录供多用户使用
+type ArchiveCache struct {
+	Dir string
+}
+
+// openArchiveCache 打开共享缓存，目录优先级：命令行 > 配置文件 > data/cache
+func openArchiveCache(baseDir string) *ArchiveCache {
+	dir := archiveCacheDir
+	if dir == "" {
+		if cfg, err := config.LoadConfig(); err == nil && cfg.CacheDir != "" {
+			dir = cfg.CacheDir
+		}
+	}
+	if dir == "" {
+		dir = filepath.Join(baseDir, "cache")
+	}
+	return &ArchiveCache{Dir: dir}
+}
+
+// path 返回指定校验和对应的缓存文件路径
+func (c *ArchiveCache) path(sha string) string {
+	sha = strings.ToLower(sha)
+	return filepath.Join(c.Dir, sha[:2], sha)
+}
+
+// sizes 返回缓存中所有文件的大小，用于在计算哈希前排除不可能重复的文件
+func (c *ArchiveCache) sizes() map[int64]bool {
+	sizes := make(map[int64]bool)
+	filepath.WalkDir(c.Dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			sizes[info.Size()] = true
+		}
+		return nil
+	})
+	return sizes
+}
+
+// Lookup 查找缓存中的安装包，校验失败的文件会被移除
+func (c *ArchiveCache) Lookup(sha string) (string, bool) {
+	if len(sha) != 64 {
+		return "", false
+	}
+	cached := c.path(sha)
+	if _, err := os.Stat(cached); err != nil {
+		return "", false
+	}
+	if err := verifyChecksum(cached, sha); err != nil {
+		fmt.Printf("⚠️ 缓存文件校验失败，将重新下载: %v\n", err)
+		c.evict(sha)
+		return "", false
+	}
+	return cached, true
+}
+
+// Writable 判断缓存目录是否可写入，只读的共享目录只能用于读取
+func (c *ArchiveCache) Writable() bool {
+	if err := os.MkdirAll(c.Dir, 0755); err != nil {
+		return false
+	}
+	f, err := os.CreateTemp(c.Dir, ".write-test-*")
+	if err != nil {
+		return false
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// Fetch 将安装包直接下载到缓存的临时文件，校验通过后放入缓存，返回缓存路径
+func (c *ArchiveCache) Fetch(url, sha string) (string, error) {
+	return c.put(sha, func(tmp string) error {
+		if err := downloadWithProgress(url, tmp); err != nil {
+			return fmt.Errorf("❌ 下载失败: %v", err)
+		}
+		return nil
+	})
+}
+
+// Store 将已校验的安装包移入缓存，不在同一磁盘时复制后删除原文件，返回缓存路径
+func (c *ArchiveCache) Store(src, sha string) (string, error) {
+	cached, err := c.put(sha, func(tmp string) error {
+		if err := os.Rename(src, tmp); err == nil {
+			return nil
+		}
+		if err := copyFile(src, tmp); err != nil {
+			return fmt.Errorf("复制到缓存失败: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if !samePath(src, cached) {
+		os.Remove(src)
+	}
+	return cached, nil
+}
+
+// put 在锁保护下由 write 写入临时文件，校验后重命名为缓存文件
+func (c *ArchiveCache) put(sha string, write func(tmp string) error) (string, error) {
+	if len(sha) != 64 {
+		return "", fmt.Errorf("无效的校验和: %s", sha)
+	}
+	cached := c.path(sha)
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		return "", fmt.Errorf("创建缓存目录失败: %v", err)
+	}
+
+	lock, err := acquireFileLock(cached+".lock", "写入共享缓存", cacheLockTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	// 其他进程可能已经写入
+	if _, err := os.Stat(cached); err == nil {
+		return cached, nil
+	}
+
+	tmp := fmt.Sprintf("%s.tmp-%d", cached, os.Getpid())
+	if err := write(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := verifyChecksum(tmp, sha); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("❌ %v", err)
+	}
+	if err := os.Rename(tmp, cached); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("写入缓存失败: %v", err)
+	}
+	return cached, nil
+}
+
+// evict 在锁保护下删除缓存文件
+func (c *ArchiveCache) evict(sha string) {
+	cached := c.path(sha)
+	lock, err := acquireFileLock(cached+".lock", "清理共享缓存", cacheLockTimeout)
+	if err != nil {
+		return
+	}
+	defer lock.Release()
+	os.Remove(cached)
+}
+
+// copyFile 复制文件内容
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,73 @@
+package version
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArchiveCacheFetch(t *testing.T) {
+	content := []byte("go archive content")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer srv.Close()
+
+	quietProgress(t)
+	cache := &ArchiveCache{Dir: t.TempDir()}
+	sha := sha256Hex(content)
+
+	cached, err := cache.Fetch(srv.URL+"/go.zip", sha)
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if cached != cache.path(sha) {
+		t.Fatalf("Fetch = %s，期望 %s", cached, cache.path(sha))
+	}
+	if got, ok := cache.Lookup(sha); !ok || got != cached {
+		t.Fatalf("Lookup = %s, %v", got, ok)
+	}
+
+	// 校验失败时不留下缓存文件或临时文件
+	bad := sha256Hex([]byte("other"))
+	if _, err := cache.Fetch(srv.URL+"/go.zip", bad); err == nil {
+		t.Fatal("校验和不匹配时应失败")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(cache.path(bad)))
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != ".lock" {
+			t.Errorf("残留文件: %s", e.Name())
+		}
+	}
+}
+
+func TestArchiveCacheStoreMovesArchive(t *testing.T) {
+	content := []byte("go archive content")
+	sha := sha256Hex(content)
+	src := filepath.Join(t.TempDir(), "go1.21.5.windows-amd64.zip")
+	if err := os.WriteFile(src, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := &ArchiveCache{Dir: t.TempDir()}
+	cached, err := cache.Store(src, sha)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if data, err := os.ReadFile(cached); err != nil || string(data) != string(content) {
+		t.Fatalf("缓存内容 = %q, %v", data, err)
+	}
+	// 安装包只保存一份
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Fatalf("原文件仍然存在: %v", err)
+	}
+}
@@ -0,0 +1,142 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	extractSizeFactor = 4                // 解压后大小约为压缩包的倍数（估算值）
+	diskSpaceMargin   = 50 * 1024 * 1024 // 额外预留空间
+)
+
+// checkDiskSpace 检查目录所在磁盘是否有足够空间，不足时列出可清理的内容
+func checkDiskSpace(baseDir, dir string, required int64, purpose string) error {
+	if required <= 0 {
+		return nil
+	}
+
+	free, err := freeDiskSpace(existingParent(dir))
+	if err != nil {
+		fmt.Printf("警告: 无法检查磁盘空间: %v\n", err)
+		return nil
+	}
+	if free >= uint64(required+diskSpaceMargin) {
+		return nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "💾 磁盘空间不足，无法%s\n", purpose)
+	fmt.Fprintf(&b, "   目录: %s\n", dir)
+	fmt.Fprintf(&b, "   需要: %s (含预留 %s)，可用: %s\n",
+		formatBytes(required+diskSpaceMargin), formatBytes(diskSpaceMargin), formatBytes(int64(free)))
+	if items := reclaimableItems(baseDir); len(items) > 0 {
+		b.WriteString("   以下内容可以清理以释放空间:\n")
+		for _, item := range items {
+			fmt.Fprintf(&b, "   • %s\n", item)
+		}
+		b.WriteString("   💡 可使用 go-version-switch -prune -dry-run 按策略预览清理\n")
+	}
+	return fmt.Errorf("%s", strings.TrimRight(b.String(), "\n"))
+}
+
+// reclaimableItems 列出可清理的安装包和非当前使用的版本
+func reclaimableItems(baseDir string) []string {
+	var items []string
+
+	downDir := filepath.Join(baseDir, "down")
+	if entries, err := os.ReadDir(downDir); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if info, err := entry.Info(); err == nil {
+				items = append(items, fmt.Sprintf("%s (%s)", filepath.Join(downDir, entry.Name()), formatBytes(info.Size())))
+			}
+		}
+	}
+
+	currentRoot := os.Getenv("GOROOT")
+	versionDir := filepath.Join(baseDir, "go-version")
+	if entries, err := os.ReadDir(versionDir); err == nil {
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			path := filepath.Join(versionDir, entry.Name())
+			if currentRoot != "" && strings.EqualFold(filepath.Clean(currentRoot), path) {
+				continue
+			}
+			items = append(items, fmt.Sprintf("%s (%s)", path, formatBytes(dirSize(path))))
+		}
+	}
+
+	return items
+}
+
+// parseReleaseSize 解析版本列表中的文件大小，如 "63MB"
+func parseReleaseSize(size string) int64 {
+	size = strings.ToUpper(strings.TrimSpace(size))
+	units := []struct {
+		suffix string
+		factor float64
+	}{
+		{"GB", 1024 * 1024 * 1024},
+		{"MB", 1024 * 1024},
+		{"KB", 1024},
+		{"B", 1},
+	}
+	for _, u := range units {
+		if strings.HasSuffix(size, u.suffix) {
+			n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(size, u.suffix)), 64)
+			if err != nil {
+				return 0
+			}
+			return int64(n * u.factor)
+		}
+	}
+	return 0
+}
+
+// dirSize 计算目录总大小
+func dirSize(path string) int64 {
+	var total int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// existingParent 返回路径自身或最近的已存在的上级目录
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// formatBytes 格式化字节数
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024*1024:
+		return fmt.Sprintf("%.1fGB", float64(n)/1024/1024/1024)
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(n)/1024/1024)
+	case n >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
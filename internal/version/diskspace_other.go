@@ -0,0 +1,18 @@
+//go:build !windows && !linux && !darwin && !freebsd
+
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// freeDiskSpace 当前平台不支持查询磁盘空间
+func freeDiskSpace(path string) (uint64, error) {
+	return 0, fmt.Errorf("暂不支持在 %s 系统上检查磁盘空间", runtime.GOOS)
+}
+
+// sameVolume 无法判断时视为同一磁盘，按所需空间之和检查
+func sameVolume(a, b string) bool {
+	return true
+}
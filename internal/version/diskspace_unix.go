@@ -0,0 +1,32 @@
+//go:build linux || darwin || freebsd
+
+package version
+
+import (
+	"os"
+	"syscall"
+)
+
+// freeDiskSpace 返回路径所在磁盘对当前用户可用的空间（字节）
+func freeDiskSpace(path string) (uint64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), nil
+}
+
+// sameVolume 判断两个路径是否位于同一文件系统，无法判断时视为相同
+func sameVolume(a, b string) bool {
+	ia, errA := os.Stat(existingParent(a))
+	ib, errB := os.Stat(existingParent(b))
+	if errA != nil || errB != nil {
+		return true
+	}
+	sa, okA := ia.Sys().(*syscall.Stat_t)
+	sb, okB := ib.Sys().(*syscall.Stat_t)
+	if !okA || !okB {
+		return true
+	}
+	return sa.Dev == sb.Dev
+}
@@ -1,134 +1,225 @@
 package version
 
 import (
-	"archive/zip"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
-	"time"
 )
 
-// DownloadProgress 下载进度结构
-type DownloadProgress struct {
-	Total      int64
-	Downloaded int64
-	StartTime  time.Time
-}
-
-// 进度条字符
-const (
-	progressWidth = 40
-	progressChar  = "█"
-	emptyChar     = "░"
-)
-
-// DownloadAndExtract 下载并解压Go版本
-func DownloadAndExtract(release *GoRelease, baseDir string) error {
+// DownloadAndExtract 下载并解压Go版本，返回安装目录
+func DownloadAndExtract(release *GoRelease, baseDir string) (string, error) {
 	// 创建下载目录
 	downloadDir := filepath.Join(baseDir, "down")
 	if err := os.MkdirAll(downloadDir, 0755); err != nil {
-		return fmt.Errorf("📁 创建下载目录失败: %v", err)
+		return "", fmt.Errorf("📁 创建下载目录失败: %v", err)
 	}
 
 	// 创建版本目录
 	versionDir := filepath.Join(baseDir, "go-version")
 	if err := os.MkdirAll(versionDir, 0755); err != nil {
-		return fmt.Errorf("📁 创建版本目录失败: %v", err)
+		return "", fmt.Errorf("📁 创建版本目录失败: %v", err)
 	}
 	//fmt.Println("输入架构 ",release.Arch)
 	// 生成标准化的文件名
 	arch := normalizeArch(release.Arch)
 	//fmt.Println("标准化架构 ",arch)
 	if arch == "" {
-		return fmt.Errorf("不支持的架构: %s", release.Arch)
+		return "", fmt.Errorf("不支持的架构: %s", release.Arch)
 	}
-	fileName := fmt.Sprintf("go%s.windows-%s.zip", release.Version, strings.ToLower(arch))
+	goos := normalizeOS(release.OS)
+	fileName := archiveFileName(release.Version, goos, arch)
 	downloadPath := filepath.Join(downloadDir, fileName)
-	fmt.Printf("📥 正在下载 Go %s (%s)...\n", release.Version, release.Arch)
-	fmt.Printf("📂 下载目录: %s\n", downloadDir)
-	fmt.Printf("📦 目标文件: %s\n", downloadPath)
 
-	// 检查是否已存在下载文件
-	if _, err := os.Stat(downloadPath); err == nil {
-		fmt.Printf("💡 发现已下载的文件: %s\n", downloadPath)
-		fmt.Printf("🔍 正在验证文件完整性...\n")
-		if err := verifyChecksum(downloadPath, release.SHA256); err == nil {
-			fmt.Printf("✅ 文件验证成功，跳过下载\n")
-		} else {
-			fmt.Printf("⚠️ 文件验证失败: %v\n", err)
-			fmt.Printf("🗑️ 删除损坏的文件...\n")
-			if err := os.Remove(downloadPath); err != nil {
-				return fmt.Errorf("删除损坏的文件失败: %v", err)
-			}
-			fmt.Printf("📥 开始重新下载...\n")
-			if err := downloadWithProgress(release.DownloadURL, downloadPath); err != nil {
-				return fmt.Errorf("❌ 下载失败: %v", err)
-			}
+	// 优先使用共享缓存；缓存可写时直接下载到缓存中，不在 data/down 中另存一份
+	cache := openArchiveCache(baseDir)
+	cached, cacheHit := cache.Lookup(release.SHA256)
+	useCache := !cacheHit && len(release.SHA256) == 64 && cache.Writable()
+	downloadTo := downloadDir
+	if useCache {
+		downloadTo = cache.Dir
+	}
+	fmt.Printf("📥 正在下载 Go %s (%s)...\n", release.Version, release.Arch)
+	fmt.Printf("📂 下载目录: %s\n", downloadTo)
+
+	// 预检查磁盘空间，避免下载或解压到一半失败；
+	// 下载目录与版本目录在同一磁盘时，安装包和解压结果需要同时存放
+	// （替换前保留的旧版本目录已占用空间，不需要额外计算）
+	archiveSize := parseReleaseSize(release.Size)
+	extractSize := archiveSize * extractSizeFactor
+	existing := false
+	if !cacheHit {
+		if _, err := os.Stat(downloadPath); err == nil {
+			existing = true
+		}
+	}
+	needDownload := !cacheHit && !existing
+	if needDownload && sameVolume(downloadTo, versionDir) {
+		if err := checkDiskSpace(baseDir, versionDir, archiveSize+extractSize, "下载并解压安装包"); err != nil {
+			return "", err
 		}
 	} else {
-		// 文件不存在，直接下载
-		fmt.Printf("📥 开始下载文件...\n")
-		if err := downloadWithProgress(release.DownloadURL, downloadPath); err != nil {
-			return fmt.Errorf("❌ 下载失败: %v", err)
+		if err := checkDiskSpace(baseDir, versionDir, extractSize, "解压安装包"); err != nil {
+			return "", err
+		}
+		if needDownload {
+			if err := checkDiskSpace(baseDir, downloadTo, archiveSize, "下载安装包"); err != nil {
+				return "", err
+			}
 		}
 	}
 
-	// 验证下载文件
-	fmt.Printf("🔍 正在验证文件完整性...\n")
-	if err := verifyChecksum(downloadPath, release.SHA256); err != nil {
-		return fmt.Errorf("❌ %v", err)
+	if cacheHit {
+		fmt.Printf("💡 共享缓存中已有该安装包，跳过下载: %s\n", cached)
+		downloadPath = cached
+	} else {
+		// 检查是否已存在下载文件
+		if existing {
+			fmt.Printf("💡 发现已下载的文件: %s\n", downloadPath)
+			fmt.Printf("🔍 正在验证文件完整性...\n")
+			if err := verifyChecksum(downloadPath, release.SHA256); err == nil {
+				fmt.Printf("✅ 文件验证成功，跳过下载\n")
+			} else {
+				fmt.Printf("⚠️ 文件验证失败: %v\n", err)
+				fmt.Printf("🗑️ 删除损坏的文件...\n")
+				if err := os.Remove(downloadPath); err != nil {
+					return "", fmt.Errorf("删除损坏的文件失败: %v", err)
+				}
+				existing = false
+			}
+		}
+
+		switch {
+		case existing && useCache:
+			// 已下载的文件移入共享缓存，供其他用户或机器复用
+			if path, err := cache.Store(downloadPath, release.SHA256); err != nil {
+				fmt.Printf("警告: 写入共享缓存失败: %v\n", err)
+			} else {
+				downloadPath = path
+			}
+		case !existing && useCache:
+			fmt.Printf("📥 开始下载文件...\n")
+			path, err := cache.Fetch(release.DownloadURL, release.SHA256)
+			if err != nil {
+				return "", err
+			}
+			fmt.Printf("✅ 文件验证成功\n")
+			downloadPath = path
+		case !existing:
+			// 共享缓存只读时下载到 data/down
+			fmt.Printf("📥 开始下载文件...\n")
+			fmt.Printf("📦 目标文件: %s\n", downloadPath)
+			if err := downloadWithProgress(release.DownloadURL, downloadPath); err != nil {
+				return "", fmt.Errorf("❌ 下载失败: %v", err)
+			}
+			fmt.Printf("🔍 正在验证文件完整性...\n")
+			if err := verifyChecksum(downloadPath, release.SHA256); err != nil {
+				return "", fmt.Errorf("❌ %v", err)
+			}
+			fmt.Printf("✅ 文件验证成功\n")
+		}
 	}
-	fmt.Printf("✅ 文件验证成功\n")
 
 	// 生成解压目标目录
-	targetDir := filepath.Join(versionDir, fmt.Sprintf("go-%s-%s", release.Version, strings.ToLower(release.Arch)))
+	targetDir := filepath.Join(versionDir, versionDirName(release.Version, goos, arch))
 	fmt.Printf("📂 解压目录: %s\n", targetDir)
 
-	// 检查并清理已存在的目录
-	if _, err := os.Stat(targetDir); err == nil {
-		fmt.Printf("🗑️  检测到已存在的目录: %s\n", targetDir)
-		fmt.Println("⚠️ 如果清理失败，请确保：")
-		fmt.Println("   1. 没有程序正在使用该目录下的文件")
-		fmt.Println("   2. 关闭所有相关的终端和编辑器")
-		fmt.Println("   3. 退出正在运行的 Go 程序")
+	if err := installFromArchive(downloadPath, goArchivePrefix, targetDir, goos, arch); err != nil {
+		return "", err
+	}
 
-		// 等待一小段时间，让用户有机会看到提示
-		time.Sleep(2 * time.Second)
+	fmt.Printf("✨ Go %s (%s) 解压成功!\n", release.Version, release.Arch)
+	return targetDir, nil
+}
 
-		if err := os.RemoveAll(targetDir); err != nil {
-			return fmt.Errorf("清理目录失败，请手动删除目录 %s 后重试: %v", targetDir, err)
-		}
+// downloadCustomArchive 从自定义地址下载安装包，指定了校验和时直接下载到共享缓存，
+// 否则（或共享缓存只读时）下载到 data/down，返回本地路径
+func downloadCustomArchive(baseDir string, opts InstallOptions) (string, error) {
+	if opts.SHA256 == "" && !opts.SkipVerify {
+		return "", fmt.Errorf("❌ 从自定义地址安装时必须使用 -sha256 指定校验和（确认来源可信时可使用 -skip-verify 跳过校验）")
 	}
 
-	if err := unzip(downloadPath, targetDir); err != nil {
-		return fmt.Errorf("❌ 解压失败: %v", err)
+	// 优先使用共享缓存
+	cache := openArchiveCache(baseDir)
+	if cached, ok := cache.Lookup(opts.SHA256); ok {
+		fmt.Printf("💡 共享缓存中已有该安装包，跳过下载: %s\n", cached)
+		return cached, nil
 	}
 
-	fmt.Printf("✨ Go %s (%s) 解压成功!\n", release.Version, release.Arch)
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", fmt.Errorf("无效的下载地址: %s", opts.URL)
+	}
+	useCache := len(opts.SHA256) == 64 && cache.Writable()
 
-	// 询问是否设置环境变量
-	fmt.Print("\n🔧 是否立即将此版本设置为系统Go环境? [Y/n] ")
-	var answer string
-	fmt.Scanln(&answer)
-	if answer == "" || strings.ToLower(answer) == "y" {
-		if err := SetAsCurrentGo(targetDir); err != nil {
-			return fmt.Errorf("❌ 设置环境变量失败: %v", err)
+	downloadDir := filepath.Join(baseDir, "down")
+	fileName := path.Base(u.Path)
+	if fileName == "." || fileName == "/" {
+		fileName = archiveFileName(opts.Version, normalizeOS(opts.OS), normalizeArch(opts.Arch))
+	}
+	downloadPath := filepath.Join(downloadDir, fileName)
+
+	if _, err := os.Stat(downloadPath); err == nil && opts.SHA256 != "" {
+		if verifyChecksum(downloadPath, opts.SHA256) == nil {
+			fmt.Printf("💡 发现已下载的文件: %s\n", downloadPath)
+			if useCache {
+				if cached, err := cache.Store(downloadPath, opts.SHA256); err == nil {
+					return cached, nil
+				}
+			}
+			return downloadPath, nil
 		}
-		fmt.Printf("✅ 环境变量设置成功\n")
-		fmt.Printf("⚠️ 注意：某些程序可能需要重启才能识别新的环境变量：\n")
-		fmt.Printf("   • 终端 (PowerShell, CMD 等)\n")
-		fmt.Printf("   • 编辑器 (VSCode, IntelliJ IDEA 等)\n")
-		fmt.Printf("   • 其他使用Go环境的应用\n")
-		fmt.Println("  • 如果环境变量设置失败，请手动设置GOROOT环境变量")
-		fmt.Println("🔄 如果需要回滚，请使用：go-version-switch -rollback")
 	}
 
-	return nil
+	fmt.Printf("📥 正在从自定义地址下载: %s\n", opts.URL)
+	if useCache {
+		return cache.Fetch(opts.URL, opts.SHA256)
+	}
+
+	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return "", fmt.Errorf("📁 创建下载目录失败: %v", err)
+	}
+	fmt.Printf("📦 目标文件: %s\n", downloadPath)
+	if err := downloadWithProgress(opts.URL, downloadPath); err != nil {
+		os.Remove(downloadPath)
+		return "", fmt.Errorf("❌ 下载失败: %v", err)
+	}
+	return downloadPath, nil
+}
+
+// archiveFileName 生成下载目录中安装包的标准文件名，Windows 使用 zip，其他系统使用 tar.gz
+func archiveFileName(version, goos, arch string) string {
+	if goos == "windows" {
+		return fmt.Sprintf("go%s.windows-%s.zip", version, strings.ToLower(arch))
+	}
+	return fmt.Sprintf("go%s.%s-%s.tar.gz", version, goos, strings.ToLower(arch))
+}
+
+// versionDirName 生成版本安装目录名，非 Windows 版本追加系统后缀以免冲突
+func versionDirName(version, goos, arch string) string {
+	if goos == "" || goos == "windows" {
+		return fmt.Sprintf("go-%s-%s", version, strings.ToLower(arch))
+	}
+	return fmt.Sprintf("go-%s-%s-%s", version, strings.ToLower(arch), goos)
+}
+
+// parseVersionDirName 从版本安装目录名解析版本号、架构和系统，是 versionDirName 的逆操作
+func parseVersionDirName(name string) (version, arch, goos string, ok bool) {
+	parts := strings.Split(strings.TrimPrefix(name, "go-"), "-")
+	if !strings.HasPrefix(name, "go-") || len(parts) < 2 || len(parts) > 3 {
+		return "", "", "", false
+	}
+	goos = "windows"
+	if len(parts) == 3 {
+		goos = parts[2]
+	}
+	return parts[0], parts[1], goos, true
 }
 
 // downloadWithProgress 带进度显示的下载
@@ -139,16 +230,18 @@ func downloadWithProgress(url string, destPath string) error {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("服务器返回: %s", resp.Status)
+	}
+
 	out, err := os.Create(destPath)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	progress := &DownloadProgress{
-		Total:     resp.ContentLength,
-		StartTime: time.Now(),
-	}
+	progress := newProgressReporter()
+	progress.Start("下载进度", resp.ContentLength, UnitBytes)
 
 	// 创建多重写入器，同时写入文件和计算进度
 	writer := &ProgressWriter{
@@ -157,16 +250,14 @@ func downloadWithProgress(url string, destPath string) error {
 	}
 
 	_, err = io.Copy(writer, resp.Body)
-	fmt.Println() // 进度条结束后换行
+	progress.Finish()
 	return err
 }
 
 // ProgressWriter 进度显示写入器
 type ProgressWriter struct {
 	Writer   io.Writer
-	Progress interface {
-		UpdateProgress(n int64)
-	}
+	Progress ProgressReporter
 }
 
 func (pw *ProgressWriter) Write(p []byte) (int, error) {
@@ -175,49 +266,17 @@ func (pw *ProgressWriter) Write(p []byte) (int, error) {
 		return n, err
 	}
 
-	pw.Progress.UpdateProgress(int64(n))
+	pw.Progress.Add(int64(n))
 	return n, nil
 }
 
-// showProgress 显示下载进度
-func (p *DownloadProgress) showProgress() {
-	percent := float64(p.Downloaded) / float64(p.Total) * 100
-	elapsed := time.Since(p.StartTime).Seconds()
-	speed := float64(p.Downloaded) / elapsed / 1024 / 1024 // MB/s
-
-	// 计算进度条
-	completed := int(float64(progressWidth) * float64(p.Downloaded) / float64(p.Total))
-	bar := strings.Repeat(progressChar, completed) + strings.Repeat(emptyChar, progressWidth-completed)
-
-	// 计算剩余时间
-	var eta string
-	if speed > 0 {
-		remainingBytes := p.Total - p.Downloaded
-		remainingSeconds := float64(remainingBytes) / (speed * 1024 * 1024)
-		eta = fmt.Sprintf("%.0fs", remainingSeconds)
-	} else {
-		eta = "计算中..."
-	}
-
-	// 使用 \r 回到行首，刷新进度显示
-	fmt.Printf("\r⏳ 下载进度: [%s] %.1f%% %.1fMB/s ETA: %s",
-		bar, percent, speed, eta)
-}
-
 // verifyChecksum 验证文件校验和
 func verifyChecksum(filePath string, expectedHash string) error {
-	file, err := os.Open(filePath)
+	actualHash, err := fileSHA256(filePath)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	hash := sha256.New()
-	if _, err := io.Copy(hash, file); err != nil {
-		return err
-	}
 
-	actualHash := hex.EncodeToString(hash.Sum(nil))
 	if !strings.EqualFold(actualHash, expectedHash) {
 		return fmt.Errorf("校验和不匹配\n期望: %s\n实际: %s", expectedHash, actualHash)
 	}
@@ -225,69 +284,19 @@ func verifyChecksum(filePath string, expectedHash string) error {
 	return nil
 }
 
-// unzip 解压文件并显示进度
-func unzip(src, dest string) error {
-	// 打开zip文件
-	r, err := zip.OpenReader(src)
+// fileSHA256 计算文件的 SHA256 校验和
+func fileSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("打开zip文件失败: %v", err)
+		return "", err
 	}
-	defer r.Close()
-
-	// 获取压缩包中的文件总数
-	totalFiles := len(r.File)
-	fmt.Printf("📦 正在解压文件 (共 %d 个文件)...\n", totalFiles)
-
-	// 创建目标目录
-	if err := os.MkdirAll(dest, 0755); err != nil {
-		return err
-	}
-
-	// 遍历压缩文件
-	for i, f := range r.File {
-		// 处理文件路径，移除第一级 "go/" 目录
-		fpath := strings.TrimPrefix(f.Name, "go/")
-
-		// 构建目标路径
-		fpath = filepath.Join(dest, fpath)
-
-		// 显示进度
-		fmt.Printf("\r📦 解压进度: %d/%d", i+1, totalFiles)
-
-		// 如果是目录，创建它
-		if f.FileInfo().IsDir() {
-			os.MkdirAll(fpath, os.ModePerm)
-			continue
-		}
-
-		// 确保父目录存在
-		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
-			return err
-		}
-
-		// 创建目标文件
-		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-
-		// 打开压缩文件
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return err
-		}
+	defer file.Close()
 
-		// 复制内容
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
-		if err != nil {
-			return err
-		}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
 	}
-	fmt.Println() // 进度显示完成后换行
-	return nil
+	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
 // ProgressReader 是一个用于跟踪读取进度的 io.Reader 包装器
@@ -303,75 +312,3 @@ func (pr *ProgressReader) Read(p []byte) (n int, err error) {
 	}
 	return
 }
-
-// DownloadManager 下载管理器
-type DownloadManager struct {
-	URL         string
-	DestPath    string
-	ProgressBar *ProgressBar
-	ContentSize int64
-	Downloaded  int64
-	StartTime   time.Time
-}
-
-func NewDownloadManager(url, destPath string) *DownloadManager {
-	return &DownloadManager{
-		URL:         url,
-		DestPath:    destPath,
-		ProgressBar: NewDefaultProgressBar(),
-	}
-}
-
-func (dm *DownloadManager) Download() error {
-	resp, err := http.Get(dm.URL)
-	if err != nil {
-		return fmt.Errorf("下载失败: %v", err)
-	}
-	defer resp.Body.Close()
-
-	dm.ContentSize = resp.ContentLength
-	dm.StartTime = time.Now()
-
-	file, err := os.Create(dm.DestPath)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %v", err)
-	}
-	defer file.Close()
-
-	writer := &ProgressWriter{
-		Writer:   file,
-		Progress: dm,
-	}
-
-	_, err = io.Copy(writer, resp.Body)
-	fmt.Println() // 进度条结束后换行
-	return err
-}
-
-func (dm *DownloadManager) UpdateProgress(n int64) {
-	dm.Downloaded += n
-	percent := float64(dm.Downloaded) / float64(dm.ContentSize)
-	speed := float64(dm.Downloaded) / time.Since(dm.StartTime).Seconds() / 1024 / 1024
-
-	bar := dm.ProgressBar.RenderProgressBar(percent)
-	eta := dm.calculateETA(speed)
-
-	fmt.Printf("\r⏳ 下载进度: [%s] %.1f%% %.1fMB/s ETA: %s",
-		bar, percent*100, speed, eta)
-}
-
-// calculateETA 计算预计剩余时间
-func (dm *DownloadManager) calculateETA(speed float64) string {
-	if speed <= 0 {
-		return "计算中..."
-	}
-
-	remainingBytes := dm.ContentSize - dm.Downloaded
-	remainingSeconds := float64(remainingBytes) / (speed * 1024 * 1024)
-	return fmt.Sprintf("%.0fs", remainingSeconds)
-}
-
-func (p *DownloadProgress) UpdateProgress(n int64) {
-	p.Downloaded += n
-	p.showProgress()
-}
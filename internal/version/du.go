@@ -0,0 +1,218 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-version-switch/internal/config"
+)
+
+// usageEntry 磁盘占用报告中的一行
+type usageEntry struct {
+	Name string
+	Path string
+	Size int64
+	Note string
+}
+
+// DiskUsage 统计数据目录的磁盘占用，并列出可回收的空间
+func DiskUsage(baseDir string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("💾 数据目录磁盘占用: %s\n", baseDir)
+	fmt.Println(strings.Repeat("=", 80))
+
+	// 按类别统计
+	categories := []usageEntry{
+		{Name: "go-version", Note: "Go版本安装目录"},
+		{Name: "down", Note: "安装包下载目录"},
+		{Name: "cache", Note: "共享下载缓存"},
+		{Name: "tools", Note: "各版本的工具"},
+		{Name: "bin", Note: "当前版本的工具"},
+		{Name: "manifest", Note: "安装文件清单"},
+		{Name: "sumdb", Note: "校验和数据库的树头和缓存"},
+		{Name: "backup_env", Note: "环境变量备份"},
+		{Name: "config", Note: "配置文件"},
+	}
+	var total int64
+	fmt.Println("📂 按类别:")
+	for i := range categories {
+		c := &categories[i]
+		c.Path = filepath.Join(baseDir, c.Name)
+		if c.Name == "cache" {
+			c.Path = openArchiveCache(baseDir).Dir
+			// 外部共享缓存不计入数据目录总量
+			if !isWithinDir(baseDir, c.Path) {
+				c.Note += " (外部目录，不计入合计)"
+			}
+		}
+		c.Size = dirSize(c.Path)
+		if c.Name != "cache" || isWithinDir(baseDir, c.Path) {
+			total += c.Size
+		}
+		fmt.Printf("   %-12s %10s  %s\n", c.Name, formatBytes(c.Size), c.Note)
+	}
+	fmt.Printf("   %-12s %10s\n", "合计", formatBytes(total))
+	fmt.Println(strings.Repeat("-", 80))
+
+	// 按版本和架构统计
+	versions, invalid := scanVersionUsage(baseDir, cfg)
+	archTotals := make(map[string]int64)
+	if len(versions) > 0 {
+		fmt.Println("📦 按版本:")
+		for _, v := range versions {
+			fmt.Printf("   %-36s %10s  %s\n", v.Name, formatBytes(v.Size), v.Note)
+			archTotals[strings.SplitN(v.Name, " ", 2)[1]] += v.Size
+		}
+		fmt.Println(strings.Repeat("-", 80))
+
+		fmt.Println("🏗️  按架构:")
+		arches := make([]string, 0, len(archTotals))
+		for arch := range archTotals {
+			arches = append(arches, arch)
+		}
+		sort.Strings(arches)
+		for _, arch := range arches {
+			fmt.Printf("   %-36s %10s\n", arch, formatBytes(archTotals[arch]))
+		}
+		fmt.Println(strings.Repeat("-", 80))
+	}
+
+	// 可回收空间
+	var reclaimable []usageEntry
+	for _, item := range pruneLeftovers(baseDir) {
+		reclaimable = append(reclaimable, usageEntry{Path: item.Path, Size: item.Size, Note: item.Reason})
+	}
+	reclaimable = append(reclaimable, invalid...)
+	reclaimable = append(reclaimable, duplicateArchives(baseDir)...)
+	for _, item := range pruneArchives(baseDir, PruneOptions{InstalledArchives: true}) {
+		if !containsPath(reclaimable, item.Path) {
+			reclaimable = append(reclaimable, usageEntry{Path: item.Path, Size: item.Size, Note: item.Reason})
+		}
+	}
+
+	if len(reclaimable) == 0 {
+		fmt.Println("✨ 没有可回收的空间")
+		return nil
+	}
+	var freeable int64
+	fmt.Println("♻️  可回收:")
+	for _, r := range reclaimable {
+		freeable += r.Size
+		fmt.Printf("   • %s (%s) - %s\n", r.Path, formatBytes(r.Size), r.Note)
+	}
+	fmt.Printf("   共 %s，可使用 -prune 或 -uninstall 清理\n", formatBytes(freeable))
+	return nil
+}
+
+// scanVersionUsage 统计每个已安装版本的大小，同时返回不完整的安装目录
+func scanVersionUsage(baseDir string, cfg *config.Config) ([]usageEntry, []usageEntry) {
+	versionDir := filepath.Join(baseDir, "go-version")
+	entries, err := os.ReadDir(versionDir)
+	if err != nil {
+		return nil, nil
+	}
+
+	var versions, invalid []usageEntry
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, arch, goos, ok := parseVersionDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		path := filepath.Join(versionDir, entry.Name())
+		size := dirSize(path)
+
+		var notes []string
+		if isCurrentGoRoot(cfg, version, path) {
+			notes = append(notes, "🎯 当前")
+		}
+		if cfg.IsPinned(version) {
+			notes = append(notes, "📌 固定")
+		}
+		if checkGoRootLayout(path, goos) != nil {
+			notes = append(notes, "⚠️ 不完整")
+			invalid = append(invalid, usageEntry{Path: path, Size: size, Note: "不完整的安装目录"})
+		}
+
+		versions = append(versions, usageEntry{
+			Name: fmt.Sprintf("%s %s/%s", version, goos, arch),
+			Path: path,
+			Size: size,
+			Note: strings.Join(notes, " "),
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return compareGoVersions(strings.Fields(versions[i].Name)[0], strings.Fields(versions[j].Name)[0]) > 0
+	})
+	return versions, invalid
+}
+
+// duplicateArchives 查找内容相同的安装包，以及共享缓存中已有的安装包
+func duplicateArchives(baseDir string) []usageEntry {
+	downloadDir := filepath.Join(baseDir, "down")
+	entries, err := os.ReadDir(downloadDir)
+	if err != nil {
+		return nil
+	}
+
+	// 先按大小分组，只对大小相同或共享缓存中有相同大小文件的安装包计算哈希
+	bySize := make(map[int64][]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !isArchiveFile(entry.Name()) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.Size() > 0 {
+			bySize[info.Size()] = append(bySize[info.Size()], filepath.Join(downloadDir, entry.Name()))
+		}
+	}
+
+	cache := openArchiveCache(baseDir)
+	cachedSizes := cache.sizes()
+	var items []usageEntry
+	for size, paths := range bySize {
+		if len(paths) < 2 && !cachedSizes[size] {
+			continue
+		}
+		sort.Strings(paths)
+		seen := make(map[string]string)
+		for _, path := range paths {
+			sha, err := fileSHA256(path)
+			if err != nil {
+				continue
+			}
+			if first, ok := seen[sha]; ok {
+				items = append(items, usageEntry{Path: path, Size: size, Note: "与 " + filepath.Base(first) + " 内容相同"})
+				continue
+			}
+			seen[sha] = path
+			if cached := cache.path(sha); !samePath(cached, path) {
+				if _, err := os.Stat(cached); err == nil {
+					items = append(items, usageEntry{Path: path, Size: size, Note: "共享缓存中已有相同文件"})
+				}
+			}
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items
+}
+
+// containsPath 判断列表中是否已包含该路径
+func containsPath(entries []usageEntry, path string) bool {
+	for _, e := range entries {
+		if samePath(e.Path, path) {
+			return true
+		}
+	}
+	return false
+}
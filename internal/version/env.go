@@ -11,15 +11,29 @@ import (
     "sort"
     "strings"
     "time"
+
+    "go-version-switch/internal/config"
 )
 
 // EnvBackup 环境变量备份结构
 type EnvBackup struct {
-    Timestamp  string `json:"timestamp"`
-    GOROOT     string `json:"goroot"`
-    GOARCH     string `json:"goarch"`
-    Path       string `json:"path"`
-    BackupFile string `json:"backup_file"`
+    SchemaVersion int    `json:"schema_version"` // 备份文件格式版本
+    Timestamp     string `json:"timestamp"`
+    GOROOT        string `json:"goroot"`
+    GOARCH        string `json:"goarch"`
+    Path          string `json:"path"`
+    BackupFile    string `json:"backup_file"`
+}
+
+// envBackupMigrations 环境变量备份的迁移，下标为迁移前的格式版本
+var envBackupMigrations = []config.Migration{
+    // 版本 0 -> 1: 字段未变化，只补充格式版本
+    func(doc any) (any, error) {
+        if _, ok := doc.(map[string]any); !ok {
+            return nil, fmt.Errorf("备份不是 JSON 对象")
+        }
+        return doc, nil
+    },
 }
 
 // SetAsCurrentGo 设置指定目录为当前Go环境（向后兼容）
@@ -36,7 +50,7 @@ func SetAsCurrentGo(goRoot string) error {
         fmt.Println("❌ 设置新环境失败，准备回滚...")
 
         // 如果设置失败，尝试回滚
-        backupDir := filepath.Join(filepath.Dir(os.Args[0]), "data", "backup_env")
+        backupDir := filepath.Join(config.DataDir(), "backup_env")
         fmt.Printf("🔍 正在查找最新的备份文件 (目录: %s)...\n", backupDir)
 
         latestBackup, rollbackErr := GetLatestBackup(backupDir)
@@ -85,9 +99,11 @@ func SetupGoEnvironment(newGoRoot string) error {
         }
     }
 
-    // 根据目录名判断架构
+    // 优先根据 go 可执行文件判断架构，无法识别时再根据目录名判断
     arch := "amd64" // 默认值
-    if strings.Contains(strings.ToLower(newGoRoot), "-x86-64") {
+    if _, goarch, err := detectBinaryPlatform(goBinaryPath(newGoRoot, runtime.GOOS)); err == nil {
+        arch = goarch
+    } else if strings.Contains(strings.ToLower(newGoRoot), "-x86-64") {
         arch = "amd64"
     } else if strings.Contains(strings.ToLower(newGoRoot), "-x86") {
         arch = "386"
@@ -132,7 +148,7 @@ func isValidGoRoot(dir string) bool {
 // backupEnvironment 备份环境变量
 func backupEnvironment() error {
     // 创建备份目录
-    backupDir := filepath.Join(filepath.Dir(os.Args[0]), "data", "backup_env")
+    backupDir := filepath.Join(config.DataDir(), "backup_env")
     if err := os.MkdirAll(backupDir, 0755); err != nil {
         return fmt.Errorf("创建备份目录失败: %v", err)
     }
@@ -172,11 +188,12 @@ func backupEnvironment() error {
     timestamp := time.Now().Format("20060102_150405")
     backupFile := filepath.Join(backupDir, fmt.Sprintf("env_backup_%s.json", timestamp))
     backup := EnvBackup{
-        Timestamp:  timestamp,
-        GOROOT:     goroot,
-        GOARCH:     currentArch,
-        Path:       path,
-        BackupFile: backupFile,
+        SchemaVersion: len(envBackupMigrations),
+        Timestamp:     timestamp,
+        GOROOT:        goroot,
+        GOARCH:        currentArch,
+        Path:          path,
+        BackupFile:    backupFile,
     }
 
     // 保存备份
@@ -346,14 +363,9 @@ $result = [UIntPtr]::Zero
 // RestoreEnvironment 恢复环境变量
 func RestoreEnvironment(backupFile string) error {
     // 读取备份文件
-    data, err := os.ReadFile(backupFile)
+    backup, err := loadEnvBackup(backupFile)
     if err != nil {
-        return fmt.Errorf("读取备份文件失败: %v", err)
-    }
-
-    var backup EnvBackup
-    if err := json.Unmarshal(data, &backup); err != nil {
-        return fmt.Errorf("解析备份数据失败: %v", err)
+        return err
     }
 
     // 恢复 GOROOT
@@ -449,16 +461,33 @@ func GetLatestBackup(backupDir string) (string, error) {
     return latestBackup, nil
 }
 
-// validateBackupFile 验证备份文件的完整性
-func validateBackupFile(backupFile string) error {
+// loadEnvBackup 读取备份文件，旧格式的备份升级后写回
+func loadEnvBackup(backupFile string) (*EnvBackup, error) {
     data, err := os.ReadFile(backupFile)
     if err != nil {
-        return fmt.Errorf("读取备份文件失败: %v", err)
+        return nil, fmt.Errorf("读取备份文件失败: %v", err)
     }
 
     var backup EnvBackup
-    if err := json.Unmarshal(data, &backup); err != nil {
-        return fmt.Errorf("解析备份文件失败: %v", err)
+    migrated, err := config.DecodeMigrated(data, envBackupMigrations, &backup)
+    if err != nil {
+        return nil, fmt.Errorf("解析备份文件失败: %v", err)
+    }
+    if migrated {
+        if data, err := json.MarshalIndent(backup, "", "    "); err == nil {
+            if err := os.WriteFile(backupFile, data, 0644); err != nil {
+                fmt.Printf("警告: 保存升级后的备份文件失败: %v\n", err)
+            }
+        }
+    }
+    return &backup, nil
+}
+
+// validateBackupFile 验证备份文件的完整性
+func validateBackupFile(backupFile string) error {
+    backup, err := loadEnvBackup(backupFile)
+    if err != nil {
+        return err
     }
 
     // 验证必要字段
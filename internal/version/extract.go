@@ -0,0 +1,557 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// goArchivePrefix 官方安装包中的顶层目录
+const goArchivePrefix = "go/"
+
+// 安装包格式
+const (
+	formatZip   = "zip"
+	formatTarGz = "tar.gz"
+)
+
+// 解压限制，防止恶意安装包耗尽磁盘
+const (
+	maxExtractSize  = 4 << 30 // 解压总大小上限 4GB
+	maxExtractFiles = 100000  // 条目数量上限
+)
+
+// extractLimits 跟踪已解压的条目数量和大小
+type extractLimits struct {
+	files int
+	size  int64
+}
+
+// add 记录一个条目，超出限制时返回错误
+func (l *extractLimits) add(name string, size int64) error {
+	l.files++
+	if l.files > maxExtractFiles {
+		return fmt.Errorf("❌ 拒绝解压 %q: 条目数量超过上限 %d", name, maxExtractFiles)
+	}
+	if size < 0 {
+		return fmt.Errorf("❌ 拒绝解压 %q: 无效的文件大小", name)
+	}
+	l.size += size
+	if l.size > maxExtractSize {
+		return fmt.Errorf("❌ 拒绝解压 %q: 解压总大小超过上限 %s", name, formatBytes(maxExtractSize))
+	}
+	return nil
+}
+
+// archiveEntryPath 计算条目的目标路径（移除顶层目录 prefix），拒绝绝对路径和逃逸出解压目录的路径
+func archiveEntryPath(dest, name, prefix string) (string, error) {
+	rel := strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), prefix)
+	if rel == "" || rel == strings.TrimSuffix(prefix, "/") {
+		return dest, nil
+	}
+
+	if strings.HasPrefix(rel, "/") || filepath.IsAbs(rel) || filepath.VolumeName(rel) != "" {
+		return "", fmt.Errorf("❌ 拒绝解压 %q: 不允许绝对路径", name)
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("❌ 拒绝解压 %q: 路径包含 \"..\"", name)
+		}
+	}
+
+	target := filepath.Join(dest, filepath.FromSlash(rel))
+	if r, err := filepath.Rel(dest, target); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("❌ 拒绝解压 %q: 路径超出解压目录", name)
+	}
+	return target, nil
+}
+
+// detectArchiveFormat 根据文件头判断安装包格式
+func detectArchiveFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("打开安装包失败: %v", err)
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return "", fmt.Errorf("读取安装包失败: %v", err)
+	}
+	switch {
+	case header[0] == 'P' && header[1] == 'K' && header[2] == 3 && header[3] == 4:
+		return formatZip, nil
+	case header[0] == 0x1f && header[1] == 0x8b:
+		return formatTarGz, nil
+	default:
+		return "", fmt.Errorf("不支持的安装包格式: %s", filepath.Base(path))
+	}
+}
+
+// extractArchive 按格式解压安装包，并移除顶层目录 prefix（官方安装包为 "go/"）
+func extractArchive(src, dest, prefix string) error {
+	format, err := detectArchiveFormat(src)
+	if err != nil {
+		return err
+	}
+	if format == formatTarGz {
+		return untar(src, dest, prefix)
+	}
+	return unzip(src, dest, prefix)
+}
+
+// archiveExtractedSize 计算安装包解压后的大小，tar.gz 无法直接获取时按压缩比估算
+func archiveExtractedSize(path string) (int64, error) {
+	format, err := detectArchiveFormat(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if format == formatTarGz {
+		info, err := os.Stat(path)
+		if err != nil {
+			return 0, err
+		}
+		return info.Size() * extractSizeFactor, nil
+	}
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var total int64
+	for _, f := range r.File {
+		total += int64(f.UncompressedSize64)
+	}
+	return total, nil
+}
+
+// pendingLink 待创建的链接，在所有文件写入完成后统一创建，
+// 这样写入文件时不会经过安装包自身创建的符号链接
+type pendingLink struct {
+	name   string // 安装包中的条目名
+	path   string // 链接所在路径
+	target string // 符号链接目标或硬链接源文件路径
+	hard   bool   // 是否为硬链接
+}
+
+// maxSymlinkTargetSize 符号链接目标的最大长度
+const maxSymlinkTargetSize = 4096
+
+// checkSymlinkTarget 校验符号链接目标为相对路径且不超出解压目录
+func checkSymlinkTarget(dest, linkPath, target, name string) error {
+	if target == "" || strings.HasPrefix(target, "/") || filepath.IsAbs(target) || filepath.VolumeName(target) != "" {
+		return fmt.Errorf("❌ 拒绝解压 %q: 符号链接目标必须为相对路径: %q", name, target)
+	}
+	resolved := filepath.Join(filepath.Dir(linkPath), filepath.FromSlash(target))
+	if !isWithinDir(dest, resolved) {
+		return fmt.Errorf("❌ 拒绝解压 %q: 符号链接指向解压目录之外: %q", name, target)
+	}
+	return nil
+}
+
+// isWithinDir 判断路径是否位于目录之内
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// createLinks 创建链接，并在创建后解析真实路径，防止通过链接链逃逸出解压目录。
+// 硬链接的源不能是符号链接：在部分系统上 os.Link 会复制符号链接本身，
+// 相对目标在新位置可能指向解压目录之外
+func createLinks(dest string, links []pendingLink) error {
+	for _, link := range links {
+		var err error
+		if link.hard {
+			if info, lerr := os.Lstat(link.target); lerr == nil && !info.Mode().IsRegular() {
+				return fmt.Errorf("❌ 拒绝解压 %q: 硬链接源不是普通文件", link.name)
+			}
+			err = os.Link(link.target, link.path)
+		} else {
+			err = os.Symlink(filepath.FromSlash(link.target), link.path)
+		}
+		if err != nil {
+			return fmt.Errorf("创建链接 %s 失败: %v", link.name, err)
+		}
+	}
+
+	realDest, err := filepath.EvalSymlinks(dest)
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		resolved, err := filepath.EvalSymlinks(link.path)
+		if err != nil {
+			// 悬空链接已通过字面路径检查
+			continue
+		}
+		if !isWithinDir(realDest, resolved) {
+			return fmt.Errorf("❌ 拒绝解压 %q: 链接解析后位于解压目录之外: %s", link.name, resolved)
+		}
+	}
+	return nil
+}
+
+// setFileMeta 设置文件权限和修改时间，不受 umask 影响
+func setFileMeta(path string, perm os.FileMode, mtime time.Time) error {
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(path, perm); err != nil {
+			return err
+		}
+	}
+	if !mtime.IsZero() {
+		return os.Chtimes(path, mtime, mtime)
+	}
+	return nil
+}
+
+// setDirTimes 在所有内容写入后恢复目录的修改时间，由深到浅处理
+func setDirTimes(dirs map[string]time.Time) {
+	paths := make([]string, 0, len(dirs))
+	for path := range dirs {
+		paths = append(paths, path)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	for _, path := range paths {
+		if mtime := dirs[path]; !mtime.IsZero() {
+			os.Chtimes(path, mtime, mtime)
+		}
+	}
+}
+
+// zipModTime 返回 zip 条目的修改时间
+func zipModTime(f *zip.File) time.Time {
+	if !f.Modified.IsZero() {
+		return f.Modified
+	}
+	return f.ModTime()
+}
+
+// zipJob 待写入的 zip 条目
+type zipJob struct {
+	file *zip.File
+	path string
+}
+
+// progressBatch 每个解压线程累计多少个文件后汇报一次进度
+const progressBatch = 64
+
+// unzip 解压文件并显示进度。先校验全部条目并创建目录，再由多个线程并发写入文件
+func unzip(src, dest, prefix string) error {
+	// 打开zip文件
+	r, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("打开zip文件失败: %v", err)
+	}
+	defer r.Close()
+
+	// 获取压缩包中的文件总数
+	totalFiles := len(r.File)
+	fmt.Printf("📦 正在解压文件 (共 %d 个文件)...\n", totalFiles)
+	progress := newProgressReporter()
+	progress.Start("解压进度", int64(totalFiles), UnitFiles)
+	defer progress.Finish()
+
+	// 创建目标目录
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	// 校验所有条目，收集目录、文件和链接
+	var limits extractLimits
+	var links []pendingLink
+	dirs := map[string]time.Time{dest: {}}
+	jobs := make([]zipJob, 0, totalFiles)
+	for _, f := range r.File {
+		// 处理文件路径，移除顶层目录并检查路径安全
+		fpath, err := archiveEntryPath(dest, f.Name, prefix)
+		if err != nil {
+			return err
+		}
+		if err := limits.add(f.Name, int64(f.UncompressedSize64)); err != nil {
+			return err
+		}
+
+		// 拒绝设备文件等特殊条目
+		mode := f.Mode()
+		if mode&os.ModeType&^(os.ModeDir|os.ModeSymlink) != 0 {
+			return fmt.Errorf("❌ 拒绝解压 %q: 不支持的文件类型 %s", f.Name, mode.Type())
+		}
+
+		switch {
+		case mode&os.ModeSymlink != 0:
+			target, err := readZipSymlink(f)
+			if err != nil {
+				return err
+			}
+			if err := checkSymlinkTarget(dest, fpath, target, f.Name); err != nil {
+				return err
+			}
+			links = append(links, pendingLink{name: f.Name, path: fpath, target: target})
+			if _, ok := dirs[filepath.Dir(fpath)]; !ok {
+				dirs[filepath.Dir(fpath)] = time.Time{}
+			}
+		case f.FileInfo().IsDir():
+			dirs[fpath] = zipModTime(f)
+		default:
+			if _, ok := dirs[filepath.Dir(fpath)]; !ok {
+				dirs[filepath.Dir(fpath)] = time.Time{}
+			}
+			jobs = append(jobs, zipJob{file: f, path: fpath})
+		}
+	}
+
+	// 先创建所有目录，避免并发写入时重复创建
+	for dir := range dirs {
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			return err
+		}
+	}
+	progress.Add(int64(totalFiles - len(jobs)))
+
+	if err := writeZipJobs(jobs, extractWorkers(), progress); err != nil {
+		return err
+	}
+	if err := createLinks(dest, links); err != nil {
+		return err
+	}
+	setDirTimes(dirs)
+	return nil
+}
+
+// readZipSymlink 读取 zip 中符号链接条目的目标路径
+func readZipSymlink(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxSymlinkTargetSize+1))
+	if err != nil {
+		return "", fmt.Errorf("读取符号链接 %s 失败: %v", f.Name, err)
+	}
+	if len(data) > maxSymlinkTargetSize {
+		return "", fmt.Errorf("❌ 拒绝解压 %q: 符号链接目标过长", f.Name)
+	}
+	return string(data), nil
+}
+
+// extractWorkers 返回解压使用的线程数，最多 8 个
+func extractWorkers() int {
+	workers := runtime.NumCPU()
+	if workers > 8 {
+		workers = 8
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// writeZipJobs 使用工作池并发写入文件，遇到错误时尽快停止
+func writeZipJobs(jobs []zipJob, workers int, progress ProgressReporter) error {
+	queue := make(chan zipJob)
+	done := make(chan struct{})
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			close(done)
+		})
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var pending int64
+			for job := range queue {
+				if err := writeZipFile(job); err != nil {
+					fail(err)
+					continue
+				}
+				if pending++; pending >= progressBatch {
+					progress.Add(pending)
+					pending = 0
+				}
+			}
+			progress.Add(pending)
+		}()
+	}
+
+feed:
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-done:
+			break feed
+		}
+	}
+	close(queue)
+	wg.Wait()
+	return firstErr
+}
+
+// writeZipFile 将单个 zip 条目写入目标路径
+func writeZipFile(job zipJob) error {
+	// 部分 zip（如模块代理生成的）不含权限信息
+	perm := job.file.Mode().Perm()
+	if perm == 0 {
+		perm = 0644
+	}
+
+	// 创建目标文件
+	outFile, err := os.OpenFile(job.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	// 打开压缩文件
+	rc, err := job.file.Open()
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+
+	// 复制内容
+	_, err = io.Copy(outFile, rc)
+	rc.Close()
+	if cerr := outFile.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = setFileMeta(job.path, perm, zipModTime(job.file))
+	}
+	if err != nil {
+		return fmt.Errorf("解压 %s 失败: %v", job.file.Name, err)
+	}
+	return nil
+}
+
+// untar 解压 tar.gz 文件并显示进度（按已读取的压缩数据计算）
+func untar(src, dest, prefix string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("打开tar.gz文件失败: %v", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📦 正在解压文件 (%s)...\n", formatBytes(info.Size()))
+	progress := newProgressReporter()
+	progress.Start("解压进度", info.Size(), UnitBytes)
+	defer progress.Finish()
+
+	reader := &ProgressReader{
+		Reader:     bufio.NewReader(file),
+		OnProgress: progress.Add,
+	}
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("读取gzip数据失败: %v", err)
+	}
+	defer gz.Close()
+
+	// 创建目标目录
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		return err
+	}
+
+	tr := tar.NewReader(gz)
+	var limits extractLimits
+	var links []pendingLink
+	dirs := map[string]time.Time{}
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("读取tar数据失败: %v", err)
+		}
+
+		// 处理文件路径，移除顶层目录并检查路径安全
+		fpath, err := archiveEntryPath(dest, hdr.Name, prefix)
+		if err != nil {
+			return err
+		}
+		if err := limits.add(hdr.Name, hdr.Size); err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(fpath, os.ModePerm); err != nil {
+				return err
+			}
+			dirs[fpath] = hdr.ModTime
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+				return err
+			}
+			outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(outFile, tr)
+			if cerr := outFile.Close(); err == nil {
+				err = cerr
+			}
+			if err == nil {
+				err = setFileMeta(fpath, hdr.FileInfo().Mode().Perm(), hdr.ModTime)
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := checkSymlinkTarget(dest, fpath, hdr.Linkname, hdr.Name); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+				return err
+			}
+			links = append(links, pendingLink{name: hdr.Name, path: fpath, target: hdr.Linkname})
+		case tar.TypeLink:
+			// 硬链接源为安装包内的另一个条目
+			source, err := archiveEntryPath(dest, hdr.Linkname, prefix)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+				return err
+			}
+			links = append(links, pendingLink{name: hdr.Name, path: fpath, target: source, hard: true})
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			return fmt.Errorf("❌ 拒绝解压 %q: 不支持的文件类型 %c", hdr.Name, hdr.Typeflag)
+		default:
+			// 其余类型（如扩展头）忽略
+		}
+	}
+
+	if err := createLinks(dest, links); err != nil {
+		return err
+	}
+	setDirTimes(dirs)
+	return nil
+}
@@ -0,0 +1,330 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const (
+	benchZipDirs       = 50
+	benchZipFilesInDir = 60 // 共 3000 个文件，与 Go 安装包的文件数量级相当
+	benchZipFileSize   = 8 * 1024
+)
+
+// writeBenchZip 在 dir 中生成包含大量小文件的 zip，结构与 Go 安装包相同（顶层目录为 go/）
+func writeBenchZip(tb testing.TB, dir string) string {
+	tb.Helper()
+
+	path := filepath.Join(dir, "bench.zip")
+	out, err := os.Create(path)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer out.Close()
+
+	content := bytes.Repeat([]byte("package bench\n// go-version-switch\n"), benchZipFileSize/36)
+	zw := zip.NewWriter(out)
+	for d := 0; d < benchZipDirs; d++ {
+		for f := 0; f < benchZipFilesInDir; f++ {
+			w, err := zw.Create(fmt.Sprintf("go/src/pkg%02d/file%03d.go", d, f))
+			if err != nil {
+				tb.Fatal(err)
+			}
+			if _, err := w.Write(content); err != nil {
+				tb.Fatal(err)
+			}
+		}
+	}
+	if err := zw.Close(); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+// zipJobsFor 按 unzip 的方式生成写入任务并创建目录
+func zipJobsFor(tb testing.TB, r *zip.ReadCloser, dest string) []zipJob {
+	tb.Helper()
+
+	jobs := make([]zipJob, 0, len(r.File))
+	for _, f := range r.File {
+		path, err := archiveEntryPath(dest, f.Name, goArchivePrefix)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		jobs = append(jobs, zipJob{file: f, path: path})
+	}
+	return jobs
+}
+
+func TestWriteZipJobs(t *testing.T) {
+	dir := t.TempDir()
+	r, err := zip.OpenReader(writeBenchZip(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	dest := filepath.Join(dir, "out")
+	jobs := zipJobsFor(t, r, dest)
+	if err := writeZipJobs(jobs, extractWorkers(), silentReporter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, job := range []zipJob{jobs[0], jobs[len(jobs)-1]} {
+		info, err := os.Stat(job.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() != int64(job.file.UncompressedSize64) {
+			t.Errorf("%s: 大小为 %d，期望 %d", job.path, info.Size(), job.file.UncompressedSize64)
+		}
+	}
+}
+
+// BenchmarkUnzip 对比单线程与工作池写入 zip 条目的耗时
+func BenchmarkUnzip(b *testing.B) {
+	r, err := zip.OpenReader(writeBenchZip(b, b.TempDir()))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer r.Close()
+
+	// 8 为 extractWorkers 的上限，写入以 I/O 为主，单核机器上也能体现差异
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			dest := filepath.Join(b.TempDir(), "out")
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				if err := os.RemoveAll(dest); err != nil {
+					b.Fatal(err)
+				}
+				jobs := zipJobsFor(b, r, dest)
+				b.StartTimer()
+
+				if err := writeZipJobs(jobs, workers, silentReporter{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// quietProgress 测试期间不输出进度
+func quietProgress(t *testing.T) {
+	t.Helper()
+	old := progressMode
+	progressMode = ProgressModeQuiet
+	t.Cleanup(func() { progressMode = old })
+}
+
+// archiveEntry 测试安装包中的条目，link 为符号链接目标或硬链接源
+type archiveEntry struct {
+	name     string
+	typeflag byte
+	link     string
+	body     string
+}
+
+// writeTarGz 生成包含指定条目的 tar.gz
+func writeTarGz(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.link, Mode: 0644}
+		switch e.typeflag {
+		case tar.TypeDir:
+			hdr.Mode = 0755
+		case tar.TypeReg:
+			hdr.Size = int64(len(e.body))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeZipEntries 生成包含指定条目的 zip，符号链接的目标写在条目内容中
+func writeZipEntries(t *testing.T, path string, entries []archiveEntry) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		body := e.body
+		switch e.typeflag {
+		case tar.TypeDir:
+			hdr.SetMode(os.ModeDir | 0755)
+		case tar.TypeSymlink:
+			hdr.SetMode(os.ModeSymlink | 0777)
+			body = e.link
+		case tar.TypeFifo:
+			hdr.SetMode(os.ModeNamedPipe | 0644)
+		case tar.TypeChar:
+			hdr.SetMode(os.ModeDevice | os.ModeCharDevice | 0644)
+		default:
+			hdr.SetMode(0644)
+		}
+		w, err := zw.CreateHeader(hdr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtractRejectsUnsafeEntries(t *testing.T) {
+	quietProgress(t)
+
+	tests := []struct {
+		name    string
+		entries []archiveEntry
+		zip     bool // 同时用 zip 格式测试（zip 没有硬链接）
+	}{
+		{
+			name:    "路径包含 ..",
+			entries: []archiveEntry{{name: "go/../evil", typeflag: tar.TypeReg, body: "x"}},
+			zip:     true,
+		},
+		{
+			name:    "绝对路径",
+			entries: []archiveEntry{{name: "/tmp/evil", typeflag: tar.TypeReg, body: "x"}},
+			zip:     true,
+		},
+		{
+			name:    "符号链接指向解压目录之外",
+			entries: []archiveEntry{{name: "go/link", typeflag: tar.TypeSymlink, link: "../../evil"}},
+			zip:     true,
+		},
+		{
+			name:    "符号链接目标为绝对路径",
+			entries: []archiveEntry{{name: "go/link", typeflag: tar.TypeSymlink, link: "/etc"}},
+			zip:     true,
+		},
+		{
+			// 按字面路径 dir/up/.. 位于解压目录内，但 dir/up 指向解压目录，解析后为其上级目录
+			name: "符号链接链",
+			entries: []archiveEntry{
+				{name: "go/dir/", typeflag: tar.TypeDir},
+				{name: "go/dir/up", typeflag: tar.TypeSymlink, link: ".."},
+				{name: "go/escape", typeflag: tar.TypeSymlink, link: "dir/up/.."},
+			},
+			zip: true,
+		},
+		{
+			// 深层目录中的相对符号链接被硬链接到顶层后，目标会指向解压目录之外
+			name: "硬链接源为符号链接",
+			entries: []archiveEntry{
+				{name: "go/f", typeflag: tar.TypeReg, body: "x"},
+				{name: "go/a/b/s", typeflag: tar.TypeSymlink, link: "../../f"},
+				{name: "go/h", typeflag: tar.TypeLink, link: "go/a/b/s"},
+			},
+		},
+		{
+			name:    "硬链接源路径包含 ..",
+			entries: []archiveEntry{{name: "go/h", typeflag: tar.TypeLink, link: "go/../../etc/passwd"}},
+		},
+		{
+			name:    "命名管道",
+			entries: []archiveEntry{{name: "go/fifo", typeflag: tar.TypeFifo}},
+			zip:     true,
+		},
+		{
+			name:    "设备文件",
+			entries: []archiveEntry{{name: "go/dev", typeflag: tar.TypeChar}},
+			zip:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		formats := []string{formatTarGz}
+		if tt.zip {
+			formats = append(formats, formatZip)
+		}
+		for _, format := range formats {
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				if runtime.GOOS == "windows" && strings.Contains(tt.name, "链接") {
+					t.Skip("Windows 上创建符号链接需要额外权限")
+				}
+
+				dir := t.TempDir()
+				src := filepath.Join(dir, "go."+format)
+				if format == formatZip {
+					writeZipEntries(t, src, tt.entries)
+				} else {
+					writeTarGz(t, src, tt.entries)
+				}
+				dest := filepath.Join(dir, "root", "go")
+
+				err := extractArchive(src, dest, goArchivePrefix)
+				if err == nil || !strings.Contains(err.Error(), "拒绝解压") {
+					t.Fatalf("extractArchive = %v，期望拒绝解压", err)
+				}
+				if _, err := os.Lstat(filepath.Join(dir, "root", "evil")); !os.IsNotExist(err) {
+					t.Fatalf("解压目录之外出现了文件: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractAllowsInternalLinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows 上创建符号链接需要额外权限")
+	}
+	quietProgress(t)
+
+	dir := t.TempDir()
+	src := filepath.Join(dir, "go.tar.gz")
+	writeTarGz(t, src, []archiveEntry{
+		{name: "go/bin/", typeflag: tar.TypeDir},
+		{name: "go/bin/go", typeflag: tar.TypeReg, body: "go"},
+		{name: "go/pkg/tool/go", typeflag: tar.TypeSymlink, link: "../../bin/go"},
+		{name: "go/bin/gofmt", typeflag: tar.TypeLink, link: "go/bin/go"},
+	})
+	dest := filepath.Join(dir, "go")
+	if err := extractArchive(src, dest, goArchivePrefix); err != nil {
+		t.Fatalf("extractArchive: %v", err)
+	}
+	for _, name := range []string{"pkg/tool/go", "bin/gofmt"} {
+		if data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name))); err != nil || string(data) != "go" {
+			t.Errorf("%s = %q, %v", name, data, err)
+		}
+	}
+}
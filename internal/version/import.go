@@ -0,0 +1,335 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"go-version-switch/internal/config"
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	Move   bool // 移动到 data/go-version，默认只登记原目录
+	DryRun bool // 只列出找到的版本
+}
+
+// importSource 其他版本管理器的安装位置
+type importSource struct {
+	Manager  string
+	Patterns []string // 版本目录的 glob 模式
+	LinkOnly bool     // 由安装程序管理的目录只允许登记，不允许移动
+}
+
+// importCandidate 找到的 Go 安装目录
+type importCandidate struct {
+	Manager  string
+	Path     string
+	Version  string
+	GOOS     string
+	Arch     string
+	LinkOnly bool
+}
+
+// ImportVersions 扫描 gvm、goenv、g、scoop、chocolatey 和 ~/sdk 中的 Go 安装，
+// 登记为可切换版本或移动到 data/go-version
+func ImportVersions(baseDir string, opts ImportOptions) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	candidates := discoverImports()
+	if len(candidates) == 0 {
+		fmt.Println("✨ 没有找到其他版本管理器安装的 Go")
+		return nil
+	}
+
+	fmt.Printf("🔍 找到 %d 个 Go 安装:\n", len(candidates))
+	var pending []importCandidate
+	for _, c := range candidates {
+		status := "可导入"
+		switch {
+		case registeredName(cfg, c.Path) != "":
+			status = "已登记为 " + registeredName(cfg, c.Path)
+		case opts.Move && !c.LinkOnly && installedDir(baseDir, c) != "":
+			status = "已安装相同版本"
+		default:
+			pending = append(pending, c)
+		}
+		fmt.Printf("   • [%s] Go %s (%s/%s) %s - %s\n", c.Manager, c.Version, c.GOOS, c.Arch, c.Path, status)
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("✨ 没有需要导入的版本")
+		return nil
+	}
+	if opts.DryRun {
+		fmt.Println("💡 这是预演 (-dry-run)，未导入任何版本")
+		return nil
+	}
+	if opts.Move {
+		fmt.Println("⚠️ 移动后原版本管理器将无法再使用这些版本")
+	}
+
+	imported := 0
+	for _, c := range pending {
+		var err error
+		inUse := opts.Move && !c.LinkOnly && isActiveGoRoot(cfg, c.Path)
+		if opts.Move && !c.LinkOnly && !inUse {
+			err = moveImport(baseDir, cfg, c)
+		} else {
+			switch {
+			case inUse:
+				fmt.Printf("💡 %s 是当前正在使用的 GOROOT，只登记不移动，切换到其他版本后可重新导入\n", c.Path)
+			case opts.Move:
+				fmt.Printf("💡 %s 由 %s 安装程序管理，只登记不移动\n", c.Path, c.Manager)
+			}
+			err = linkImport(cfg, c)
+		}
+		if err != nil {
+			fmt.Printf("❌ 导入 %s 失败: %v\n", c.Path, err)
+			continue
+		}
+		imported++
+	}
+
+	fmt.Printf("✅ 已导入 %d 个版本，使用 -list 查看，-use <版本> 切换\n", imported)
+	return nil
+}
+
+// importSources 返回各版本管理器的安装位置，支持各自的根目录环境变量
+func importSources() []importSource {
+	home, _ := os.UserHomeDir()
+	root := func(env, def string) string {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+		return filepath.Join(home, def)
+	}
+
+	sources := []importSource{
+		{Manager: "gvm", Patterns: []string{filepath.Join(root("GVM_ROOT", ".gvm"), "gos", "go*")}},
+		{Manager: "goenv", Patterns: []string{filepath.Join(root("GOENV_ROOT", ".goenv"), "versions", "*")}},
+		{Manager: "g", Patterns: []string{filepath.Join(home, ".g", "versions", "*")}},
+		{Manager: "scoop", Patterns: []string{filepath.Join(root("SCOOP", "scoop"), "apps", "go", "*")}, LinkOnly: true},
+		{Manager: "sdk", Patterns: []string{filepath.Join(home, "sdk", "go*")}},
+	}
+	if runtime.GOOS == "windows" {
+		programFiles := os.Getenv("ProgramFiles")
+		if programFiles == "" {
+			programFiles = `C:\Program Files`
+		}
+		tools := os.Getenv("ChocolateyToolsLocation")
+		if tools == "" {
+			tools = `C:\tools`
+		}
+		sources = append(sources, importSource{
+			Manager:  "chocolatey",
+			Patterns: []string{filepath.Join(programFiles, "Go"), filepath.Join(tools, "go")},
+			LinkOnly: true,
+		})
+	}
+	return sources
+}
+
+// discoverImports 查找并验证所有候选目录，按管理器和版本排序
+func discoverImports() []importCandidate {
+	var candidates []importCandidate
+	seen := make(map[string]bool)
+
+	for _, src := range importSources() {
+		for _, pattern := range src.Patterns {
+			matches, _ := filepath.Glob(pattern)
+			for _, dir := range matches {
+				// g 等工具把 GOROOT 放在版本目录下的 go 子目录中
+				for _, goRoot := range []string{dir, filepath.Join(dir, "go")} {
+					resolved, err := filepath.EvalSymlinks(goRoot)
+					if err != nil || seen[resolved] || validateGoRootPath(goRoot) != nil {
+						continue
+					}
+					seen[resolved] = true
+
+					c, err := inspectImport(src, goRoot)
+					if err != nil {
+						fmt.Printf("⚠️ 跳过 %s: %v\n", goRoot, err)
+						continue
+					}
+					candidates = append(candidates, c)
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Manager != candidates[j].Manager {
+			return candidates[i].Manager < candidates[j].Manager
+		}
+		return compareGoVersions(candidates[i].Version, candidates[j].Version) > 0
+	})
+	return candidates
+}
+
+// inspectImport 识别目录的版本和平台
+func inspectImport(src importSource, goRoot string) (importCandidate, error) {
+	version, err := detectGoRootVersion(goRoot)
+	if err != nil {
+		return importCandidate{}, err
+	}
+	if _, ok := parseGoVersion(version); !ok {
+		return importCandidate{}, fmt.Errorf("无法识别的版本号: %s", version)
+	}
+
+	goos, goarch, err := detectBinaryPlatform(goBinaryPath(goRoot, runtime.GOOS))
+	if err != nil || goarch == "" {
+		goos, goarch = runtime.GOOS, runtime.GOARCH
+	}
+	abs, err := filepath.Abs(goRoot)
+	if err != nil {
+		return importCandidate{}, err
+	}
+	return importCandidate{
+		Manager:  src.Manager,
+		Path:     abs,
+		Version:  version,
+		GOOS:     goos,
+		Arch:     normalizeArch(goarch),
+		LinkOnly: src.LinkOnly,
+	}, nil
+}
+
+// registeredName 返回已登记到该目录的版本名称
+func registeredName(cfg *config.Config, path string) string {
+	for name, p := range cfg.Versions {
+		if samePath(p, path) {
+			return name
+		}
+	}
+	return ""
+}
+
+// isActiveGoRoot 判断目录是否为正在使用的 GOROOT：环境变量 GOROOT、
+// 配置中的当前版本，或当前 shell 中 go env GOROOT 的结果（如 goenv 等管理器选中的版本）
+func isActiveGoRoot(cfg *config.Config, path string) bool {
+	roots := []string{os.Getenv("GOROOT")}
+	if cfg.CurrentVersion != "" {
+		roots = append(roots, cfg.Versions[cfg.CurrentVersion])
+	}
+	if goBin, err := exec.LookPath("go"); err == nil {
+		if output, err := exec.Command(goBin, "env", "GOROOT").Output(); err == nil {
+			roots = append(roots, strings.TrimSpace(string(output)))
+		}
+	}
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		resolved = path
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if r, err := filepath.EvalSymlinks(root); err == nil {
+			root = r
+		}
+		if samePath(root, resolved) {
+			return true
+		}
+	}
+	return false
+}
+
+// installedDir 返回 data/go-version 中相同版本的目录，不存在时返回空
+func installedDir(baseDir string, c importCandidate) string {
+	dir := filepath.Join(baseDir, "go-version", versionDirName(c.Version, c.GOOS, c.Arch))
+	if _, err := os.Stat(dir); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// importName 选择登记名称，版本号已被占用时加上管理器前缀
+func importName(cfg *config.Config, c importCandidate) (string, error) {
+	for _, name := range []string{c.Version, c.Manager + "-" + c.Version} {
+		if _, ok := cfg.Versions[name]; !ok {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("名称 %s 和 %s-%s 均已被占用", c.Version, c.Manager, c.Version)
+}
+
+// linkImport 登记原目录，不复制文件
+func linkImport(cfg *config.Config, c importCandidate) error {
+	name, err := importName(cfg, c)
+	if err != nil {
+		return err
+	}
+	if err := cfg.AddVersion(name, c.Path); err != nil {
+		return fmt.Errorf("保存版本信息失败: %v", err)
+	}
+	fmt.Printf("🔗 已登记 %s -> %s\n", name, c.Path)
+	return nil
+}
+
+// moveImport 将目录移动到 data/go-version，跨磁盘时复制后删除原目录
+func moveImport(baseDir string, cfg *config.Config, c importCandidate) error {
+	if _, ok := cfg.Versions[c.Version]; ok {
+		return fmt.Errorf("版本 %s 已登记为其他目录", c.Version)
+	}
+	if isActiveGoRoot(cfg, c.Path) {
+		return fmt.Errorf("%s 是当前正在使用的 GOROOT，不能移动", c.Path)
+	}
+
+	targetDir := filepath.Join(baseDir, "go-version", versionDirName(c.Version, c.GOOS, c.Arch))
+	if err := os.MkdirAll(filepath.Dir(targetDir), 0755); err != nil {
+		return fmt.Errorf("创建版本目录失败: %v", err)
+	}
+	staging := stagingDirFor(targetDir)
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("清理临时目录失败: %v", err)
+	}
+
+	fmt.Printf("📦 正在移动 %s -> %s\n", c.Path, targetDir)
+	copied := false
+	if err := os.Rename(c.Path, staging); err != nil {
+		if err := checkDiskSpace(baseDir, targetDir, dirSize(c.Path), "复制 Go 目录"); err != nil {
+			return err
+		}
+		if err := copySourceTree(c.Path, staging); err != nil {
+			os.RemoveAll(staging)
+			return fmt.Errorf("复制目录失败: %v", err)
+		}
+		copied = true
+	}
+
+	if copied {
+		if err := commitStagedInstall(staging, targetDir); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(c.Path); err != nil {
+			fmt.Printf("⚠️ 删除原目录失败，可稍后手动删除: %s (%v)\n", c.Path, err)
+		}
+	} else if err := swapStagedInstall(staging, targetDir); err != nil {
+		// 临时目录是原目录的唯一副本，不能删除，移回原位置
+		if rerr := os.Rename(staging, c.Path); rerr != nil {
+			return fmt.Errorf("%v；移回原位置也失败，文件保留在: %s (%v)", err, staging, rerr)
+		}
+		return err
+	}
+
+	m, err := newInstallManifest(targetDir, targetDir, "", "")
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	saveInstallManifest(targetDir, m)
+	if err := cfg.AddVersion(c.Version, targetDir); err != nil {
+		return fmt.Errorf("保存版本信息失败: %v", err)
+	}
+	fmt.Printf("✅ 已导入 Go %s -> %s\n", c.Version, targetDir)
+	return nil
+}
@@ -0,0 +1,70 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-version-switch/internal/config"
+)
+
+func TestMoveImportRefusesActiveGoRoot(t *testing.T) {
+	dir := t.TempDir()
+	goRoot := filepath.Join(dir, "sdk", "go1.21.5")
+	if err := os.MkdirAll(filepath.Join(goRoot, "bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟 goenv 等管理器的 shim：当前 shell 中 go env GOROOT 指向候选目录
+	shimDir := filepath.Join(dir, "shim")
+	if err := os.MkdirAll(shimDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" {
+		shim := "#!/bin/sh\necho " + goRoot + "\n"
+		if err := os.WriteFile(filepath.Join(shimDir, "go"), []byte(shim), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		goroot  string
+		path    string
+		current string
+		active  bool
+	}{
+		{name: "环境变量 GOROOT", goroot: goRoot, active: true},
+		{name: "配置中的当前版本", current: goRoot, active: true},
+		{name: "go env GOROOT", path: shimDir, active: runtime.GOOS != "windows"},
+		{name: "未在使用", active: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOROOT", tt.goroot)
+			t.Setenv("PATH", tt.path)
+			cfg := &config.Config{Versions: make(map[string]string)}
+			if tt.current != "" {
+				cfg.CurrentVersion = "sdk-1.21.5"
+				cfg.Versions["sdk-1.21.5"] = tt.current
+			}
+
+			if got := isActiveGoRoot(cfg, goRoot); got != tt.active {
+				t.Fatalf("isActiveGoRoot = %v，期望 %v", got, tt.active)
+			}
+			if !tt.active {
+				return
+			}
+
+			baseDir := filepath.Join(dir, "data")
+			c := importCandidate{Manager: "sdk", Path: goRoot, Version: "1.21.5", GOOS: "linux", Arch: "amd64"}
+			if err := moveImport(baseDir, cfg, c); err == nil {
+				t.Fatal("不应移动正在使用的 GOROOT")
+			}
+			if _, err := os.Stat(goRoot); err != nil {
+				t.Fatalf("正在使用的 GOROOT 被移动: %v", err)
+			}
+		})
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 
 	"go-version-switch/internal/config"
 )
@@ -17,8 +17,16 @@ import (
 // InstallOptions 安装选项
 type InstallOptions struct {
 	Version string // 版本号
+	OS      string // 目标操作系统 (windows/linux/darwin)，为空时使用当前系统
 	Arch    string // 架构
 	ZipPath string // 本地zip文件路径，如果指定则优先使用本地文件
+	URL     string // 自定义安装包下载地址，下载后按本地安装包流程安装
+	SHA256  string // 期望的 SHA256 校验和，指定后不再查询版本索引
+	Proxy   string // 模块代理地址，指定后从 golang.org/toolchain 模块安装，"env" 表示使用 GOPROXY
+	H1      string // 期望的模块哈希 (go.sum 格式，h1:...)，为空时从代理的校验和数据库查询
+
+	SkipVerify bool // 跳过本地安装包的校验和检查
+	NoPrompt   bool // 安装后不询问是否设置为系统Go环境，由调用方决定是否切换
 }
 
 // InstallVersion 优化后的安装函数
@@ -28,15 +36,30 @@ func InstallVersion(baseDir string, opts InstallOptions) error {
 		return err
 	}
 
+	// 从模块代理安装
+	if opts.Proxy != "" {
+		if err := installFromProxy(baseDir, opts); err != nil {
+			return err
+		}
+		return finishInstall(baseDir, opts)
+	}
+
 	var targetRelease *GoRelease
 	var err error
 
-	// 如果指定了本地zip文件，跳过在线查找
+	// 从自定义地址下载，之后按本地安装包流程处理
+	if opts.URL != "" {
+		opts.ZipPath, err = downloadCustomArchive(baseDir, opts)
+		if err != nil {
+			return err
+		}
+	}
+
+	// 如果指定了本地zip文件，从版本索引中查找并校验
 	if opts.ZipPath != "" {
-		targetRelease = &GoRelease{
-			Version: opts.Version,
-			OS:      "windows",
-			Arch:    opts.Arch,
+		targetRelease, err = verifyLocalArchive(baseDir, opts)
+		if err != nil {
+			return err
 		}
 	} else {
 		// 查找目标版本
@@ -52,8 +75,21 @@ func InstallVersion(baseDir string, opts InstallOptions) error {
 		return err
 	}
 
-	// 保存版本信息
-	return saveVersionConfig(baseDir, opts)
+	return finishInstall(baseDir, opts)
+}
+
+// finishInstall 保存版本信息并安装配置中的工具，之后再询问是否切换，
+// 切换时工具已安装完成，只需链接
+func finishInstall(baseDir string, opts InstallOptions) error {
+	if err := saveVersionConfig(baseDir, opts); err != nil {
+		return err
+	}
+	if err := installVersionTools(baseDir, opts); err != nil {
+		return err
+	}
+	versionDir := filepath.Join(baseDir, "go-version",
+		versionDirName(opts.Version, normalizeOS(opts.OS), normalizeArch(opts.Arch)))
+	return promptSetCurrentGo(baseDir, opts, versionDir)
 }
 
 // LocalFileHandler 本地文件处理器
@@ -70,8 +106,7 @@ func NewLocalFileHandler(baseDir string, opts InstallOptions, release *GoRelease
 		localPath = opts.ZipPath
 	} else {
 		downloadDir := filepath.Join(baseDir, "down")
-		filename := fmt.Sprintf("go%s.windows-%s.zip",
-			opts.Version, strings.ToLower(opts.Arch))
+		filename := archiveFileName(opts.Version, normalizeOS(opts.OS), normalizeArch(opts.Arch))
 		localPath = filepath.Join(downloadDir, filename)
 	}
 
@@ -103,7 +138,7 @@ func (h *LocalFileHandler) handleLocalZip() error {
 	fmt.Printf("🔄 正在安装 Go %s (%s)...\n", h.Opts.Version, h.Opts.Arch)
 
 	// 解压并安装
-	installDir, err := extractGo(h.Opts.ZipPath, h.Opts.Version, h.Opts.Arch)
+	installDir, err := extractGo(h.BaseDir, h.Opts.ZipPath, goArchivePrefix, h.Opts.Version, normalizeOS(h.Opts.OS), h.Opts.Arch)
 	if err != nil {
 		return fmt.Errorf("解压安装包失败: %v", err)
 	}
@@ -136,24 +171,86 @@ func UseVersion(baseDir string, opts InstallOptions) error {
 	}
 
 	// 检查版本是否已安装
-	versionDir := filepath.Join(baseDir, "go-version", fmt.Sprintf("go-%s-%s", opts.Version, strings.ToLower(arch)))
+	versionDir := filepath.Join(baseDir, "go-version", versionDirName(opts.Version, normalizeOS(opts.OS), arch))
 	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
-		return fmt.Errorf("版本 %s (%s) 未安装，请先安装", opts.Version, arch)
+		// 通过 -link 登记的外部目录
+		linked, ok := cfg.Versions[opts.Version]
+		if !ok || isManagedPath(baseDir, linked) {
+			return fmt.Errorf("版本 %s (%s) 未安装，请先安装", opts.Version, arch)
+		}
+		if err := validateGoRootPath(linked); err != nil {
+			return fmt.Errorf("登记的目录 %s 已失效: %v", linked, err)
+		}
+		versionDir = linked
+		if _, goarch, err := detectBinaryPlatform(goBinaryPath(linked, runtime.GOOS)); err == nil {
+			arch = normalizeArch(goarch)
+		}
 	}
 
 	// 设置为当前Go环境
-	if err := SetAsCurrentGo(versionDir); err != nil {
+	if err := activateGoRoot(baseDir, opts.Version, versionDir); err != nil {
 		return fmt.Errorf("切换版本失败: %v", err)
 	}
 
+	fmt.Printf("✅ 已成功切换到 Go %s (%s)\n", opts.Version, arch)
+	fmt.Printf("⚠️ 请重启终端和编辑器以使更改生效\n")
+
+	return nil
+}
+
+// activateGoRoot 设置为系统Go环境，记录当前版本并切换该版本编译的工具，
+// 所有改变当前 GOROOT 的操作都通过此函数，避免 data/bin 中留下其他版本的工具
+func activateGoRoot(baseDir, version, goRoot string) error {
+	// 切换前补装缺失的工具（例如配置工具列表之前安装的版本），已安装的不会重新编译
+	if err := InstallTools(baseDir, goRoot, false); err != nil {
+		fmt.Printf("⚠️ 安装工具失败: %v\n", err)
+	}
+
+	if err := SetAsCurrentGo(goRoot); err != nil {
+		return err
+	}
+
 	// 更新配置中的当前版本
-	if err := cfg.SetCurrentVersion(opts.Version); err != nil {
-		return fmt.Errorf("保存当前版本信息失败: %v", err)
+	if version != "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			return fmt.Errorf("加载配置失败: %v", err)
+		}
+		if err := cfg.SetCurrentVersion(version); err != nil {
+			return fmt.Errorf("保存当前版本信息失败: %v", err)
+		}
 	}
 
-	fmt.Printf("✅ 已成功切换到 Go %s (%s)\n", opts.Version, arch)
-	fmt.Printf("⚠️ 请重启终端和编辑器以使更改生效\n")
+	// 切换该版本编译的工具
+	if err := LinkTools(baseDir, goRoot); err != nil {
+		fmt.Printf("⚠️ 切换工具失败: %v\n", err)
+	}
+	return nil
+}
+
+// promptSetCurrentGo 安装完成后询问是否设置为系统Go环境，NoPrompt 时不询问
+func promptSetCurrentGo(baseDir string, opts InstallOptions, goRoot string) error {
+	if opts.NoPrompt {
+		return nil
+	}
+
+	fmt.Print("\n🔧 是否立即将此版本设置为系统Go环境? [Y/n] ")
+	var answer string
+	fmt.Scanln(&answer)
+	if answer != "" && strings.ToLower(answer) != "y" {
+		return nil
+	}
 
+	if err := activateGoRoot(baseDir, opts.Version, goRoot); err != nil {
+		return fmt.Errorf("❌ 设置环境变量失败: %v", err)
+	}
+	fmt.Printf("✅ 环境变量设置成功\n")
+	fmt.Printf("⚠️ 注意：某些程序可能需要重启才能识别新的环境变量：\n")
+	fmt.Printf("   • 终端 (PowerShell, CMD 等)\n")
+	fmt.Printf("   • 编辑器 (VSCode, IntelliJ IDEA 等)\n")
+	fmt.Printf("   • 其他使用Go环境的应用\n")
+	fmt.Println("  • 如果环境变量设置失败，请手动设置GOROOT环境变量")
+	fmt.Println("🔄 如果需要回滚，请使用：go-version-switch -rollback")
 	return nil
 }
 
@@ -185,58 +282,32 @@ func verifyDownloadedFile(filePath string, expectedHash string) error {
 	return nil
 }
 
-// extractGo 解压Go安装包
-func extractGo(zipPath, version, arch string) (string, error) {
+// extractGo 解压Go安装包到 data/go-version，prefix 为安装包中的顶层目录
+func extractGo(baseDir, zipPath, prefix, version, goos, arch string) (string, error) {
 	// 构建解压目录
-	extractDir := filepath.Join(filepath.Dir(os.Args[0]), "data", "go-version")
+	extractDir := filepath.Join(baseDir, "go-version")
 	if err := os.MkdirAll(extractDir, 0755); err != nil {
 		return "", fmt.Errorf("创建解压目录失败: %v", err)
 	}
 
 	// 目标目录
-	targetDir := filepath.Join(extractDir, fmt.Sprintf("go-%s-%s", version, arch))
+	targetDir := filepath.Join(extractDir, versionDirName(version, goos, arch))
 
 	fmt.Printf("📂 解压目录: %s\n", targetDir)
-	// 检查并清理已存在的目录
-	if _, err := os.Stat(targetDir); err == nil {
-		fmt.Printf("🗑️  检测到已存在的目录: %s\n", targetDir)
-		fmt.Println("⚠️ 如果清理失败，请确保：")
-		fmt.Println("   1. 没有程序正在使用该目录下的文件")
-		fmt.Println("   2. 关闭所有相关的终端和编辑器")
-		fmt.Println("   3. 退出正在运行的 Go 程序")
-
-		// 等待一小段时间，让用户有机会看到提示
-		time.Sleep(2 * time.Second)
-
-		if err := os.RemoveAll(targetDir); err != nil {
-			return "", fmt.Errorf("清理目录失败，请手动删除目录 %s 后重试: %v", targetDir, err)
+
+	// 检查磁盘空间
+	if size, err := archiveExtractedSize(zipPath); err == nil {
+		if err := checkDiskSpace(filepath.Dir(extractDir), extractDir, size, "解压安装包"); err != nil {
+			return "", err
 		}
 	}
 
-	// 解压文件
-	if err := unzip(zipPath, targetDir); err != nil {
-		return "", fmt.Errorf("❌ 解压失败: %v", err)
+	// 解压到临时目录，验证后替换
+	if err := installFromArchive(zipPath, prefix, targetDir, goos, arch); err != nil {
+		return "", err
 	}
 	fmt.Printf("✅ 解压完成，安装目录: %s\n", targetDir)
 	fmt.Printf("✨ Go %s (%s) 解压成功!\n", version, arch)
-
-	// 询问是否设置环境变量
-	fmt.Print("\n🔧 是否立即将此版本设置为系统Go环境? [Y/n] ")
-	var answer string
-	fmt.Scanln(&answer)
-	if answer == "" || strings.ToLower(answer) == "y" {
-		if err := SetAsCurrentGo(targetDir); err != nil {
-			return "", fmt.Errorf("❌ 设置环境变量失败: %v", err)
-		}
-		fmt.Printf("✅ 环境变量设置成功\n")
-		fmt.Printf("⚠️ 注意：某些程序可能需要重启才能识别新的环境变量：\n")
-		fmt.Printf("   • 终端 (PowerShell, CMD 等)\n")
-		fmt.Printf("   • 编辑器 (VSCode, IntelliJ IDEA 等)\n")
-		fmt.Printf("   • 其他使用Go环境的应用\n")
-		fmt.Println("  • 如果环境变量设置失败，请手动设置GOROOT环境变量")
-		fmt.Println("🔄 如果需要回滚，请使用：go-version-switch -rollback")
-
-	}
 	return targetDir, nil
 }
 
@@ -261,6 +332,13 @@ func prepareInstallEnvironment(baseDir string, opts *InstallOptions) error {
 	if arch == "" {
 		return fmt.Errorf("不支持的架构: %s", opts.Arch)
 	}
+	opts.Arch = arch
+
+	goos := normalizeOS(opts.OS)
+	if goos == "" {
+		return fmt.Errorf("不支持的操作系统: %s (可选: windows/linux/darwin)", opts.OS)
+	}
+	opts.OS = goos
 
 	return nil
 }
@@ -276,19 +354,106 @@ func findTargetRelease(baseDir string, opts InstallOptions) (*GoRelease, error)
 	// 查找指定版本和架构的发布版本
 	arch := normalizeArch(opts.Arch)
 	// fmt.Println("标准化架构 ",arch)
+	goos := normalizeOS(opts.OS)
 	for _, v := range list.Versions {
-		if v.Version == opts.Version && strings.EqualFold(v.Arch, arch) {
+		if v.Version == opts.Version && normalizeOS(v.OS) == goos && strings.EqualFold(v.Arch, arch) {
 			return v, nil
 		}
 	}
 
-	return nil, fmt.Errorf("未找到版本 %s 的 %s 架构版本", opts.Version, arch)
+	return nil, fmt.Errorf("未找到版本 %s 的 %s/%s 版本", opts.Version, goos, arch)
+}
+
+// verifyLocalArchive 在版本索引中查找本地安装包并校验 SHA256
+func verifyLocalArchive(baseDir string, opts InstallOptions) (*GoRelease, error) {
+	fileName := filepath.Base(opts.ZipPath)
+
+	var release *GoRelease
+	if opts.SHA256 != "" {
+		release = &GoRelease{
+			Version:     opts.Version,
+			OS:          opts.OS,
+			Arch:        opts.Arch,
+			SHA256:      opts.SHA256,
+			DownloadURL: opts.URL,
+		}
+	} else {
+		release = lookupLocalArchive(baseDir, opts)
+	}
+	if release == nil {
+		if !opts.SkipVerify {
+			// 离线索引与安装包放在一起，能替换安装包的人也能修改其中的校验和，不能作为校验依据
+			if sha := prefetchIndexSHA256(opts.ZipPath); sha != "" {
+				return nil, fmt.Errorf("❌ 安装包 %s 不在官方版本列表中，拒绝安装\n   离线索引 (%s) 记录的校验和为 %s，与可信来源核对后可使用 -sha256 指定", fileName, prefetchIndexFile, sha)
+			}
+			return nil, fmt.Errorf("❌ 安装包 %s 不在已知版本索引中，拒绝安装（可使用 -sha256 指定校验和，确认来源可信时可使用 -skip-verify 跳过校验）", fileName)
+		}
+		fmt.Printf("⚠️ 安装包 %s 不在已知版本索引中，已按要求跳过校验\n", fileName)
+		return &GoRelease{
+			Version: opts.Version,
+			OS:      opts.OS,
+			Arch:    opts.Arch,
+		}, nil
+	}
+
+	fmt.Printf("🔍 正在验证本地安装包: %s\n", fileName)
+	verifier := &FileVerifier{
+		FilePath:     opts.ZipPath,
+		ExpectedHash: release.SHA256,
+	}
+	if err := verifier.Verify(); err != nil {
+		if !opts.SkipVerify {
+			return nil, fmt.Errorf("❌ 本地安装包校验失败，拒绝安装（确认来源可信时可使用 -skip-verify 跳过校验）: %v", err)
+		}
+		fmt.Printf("⚠️ 本地安装包校验失败，已按要求跳过: %v\n", err)
+		return release, nil
+	}
+	fmt.Println("✅ 本地安装包验证成功")
+	return release, nil
+}
+
+// lookupLocalArchive 按版本、架构和文件名在缓存的官方版本列表中查找本地安装包对应的发布信息
+func lookupLocalArchive(baseDir string, opts InstallOptions) *GoRelease {
+	fileName := filepath.Base(opts.ZipPath)
+	goos := normalizeOS(opts.OS)
+	arch := normalizeArch(opts.Arch)
+
+	list, err := GetVersionList(baseDir, false)
+	if err != nil {
+		fmt.Printf("警告: 获取版本索引失败: %v\n", err)
+	} else {
+		var matched *GoRelease
+		for _, v := range list.Versions {
+			// 文件名与官方文件名一致时直接使用
+			if strings.EqualFold(path.Base(v.DownloadURL), fileName) {
+				return v
+			}
+			if matched == nil && v.Version == opts.Version && normalizeOS(v.OS) == goos && strings.EqualFold(v.Arch, arch) {
+				matched = v
+			}
+		}
+		if matched != nil {
+			return matched
+		}
+	}
+	return nil
+}
+
+// prefetchIndexSHA256 返回安装包所在目录的离线索引 (index.json) 中记录的校验和，只用于提示
+func prefetchIndexSHA256(archivePath string) string {
+	fileName := filepath.Base(archivePath)
+	for _, e := range loadPrefetchIndex(filepath.Dir(archivePath)).Entries {
+		if strings.EqualFold(e.File, fileName) {
+			return e.SHA256
+		}
+	}
+	return ""
 }
 
 // saveVersionConfig 保存版本配置
 func saveVersionConfig(baseDir string, opts InstallOptions) error {
 	versionDir := filepath.Join(baseDir, "go-version",
-		fmt.Sprintf("go-%s-%s", opts.Version, strings.ToLower(opts.Arch)))
+		versionDirName(opts.Version, normalizeOS(opts.OS), normalizeArch(opts.Arch)))
 
 	cfg, err := config.LoadConfig()
 	if err != nil {
@@ -314,11 +479,9 @@ func (h *LocalFileHandler) handleExistingFile() error {
 
 	if err := verifier.Verify(); err == nil {
 		fmt.Println("✅ 本地文件验证成功，将直接使用")
-		_, err := extractGo(h.LocalPath, h.Opts.Version, h.Opts.Arch)
-		if err != nil {
+		if _, err := extractGo(h.BaseDir, h.LocalPath, goArchivePrefix, h.Opts.Version, normalizeOS(h.Opts.OS), h.Opts.Arch); err != nil {
 			return fmt.Errorf("%v", err)
 		}
-
 		return nil
 	} else {
 		fmt.Printf("⚠️ 本地文件验证失败: %v\n", err)
@@ -329,7 +492,7 @@ func (h *LocalFileHandler) handleExistingFile() error {
 }
 
 func (h *LocalFileHandler) handleNewDownload() error {
-	if err := DownloadAndExtract(h.TargetRelease, h.BaseDir); err != nil {
+	if _, err := DownloadAndExtract(h.TargetRelease, h.BaseDir); err != nil {
 		return fmt.Errorf("%v", err)
 	}
 	return nil
@@ -0,0 +1,143 @@
+package version
+
+import (
+	"bufio"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"go-version-switch/internal/config"
+)
+
+// LinkVersion 将已解压的 GOROOT 目录登记为可切换版本，不复制任何文件
+func LinkVersion(baseDir, name, goRoot string) error {
+	if name == "" {
+		return fmt.Errorf("未指定版本名称")
+	}
+
+	goRoot, err := filepath.Abs(goRoot)
+	if err != nil {
+		return fmt.Errorf("解析路径失败: %v", err)
+	}
+
+	fmt.Printf("🔍 正在验证目录: %s\n", goRoot)
+	if err := validateGoRootPath(goRoot); err != nil {
+		return err
+	}
+
+	goVersion, err := detectGoRootVersion(goRoot)
+	if err != nil {
+		fmt.Printf("⚠️ 无法识别版本号: %v\n", err)
+	}
+	goos, goarch, err := detectBinaryPlatform(goBinaryPath(goRoot, runtime.GOOS))
+	if err != nil {
+		fmt.Printf("⚠️ 无法识别架构: %v\n", err)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if existing, ok := cfg.Versions[name]; ok && !strings.EqualFold(filepath.Clean(existing), goRoot) {
+		fmt.Printf("⚠️ 名称 %s 已指向 %s，将被覆盖\n", name, existing)
+	}
+	if err := cfg.AddVersion(name, goRoot); err != nil {
+		return fmt.Errorf("保存版本信息失败: %v", err)
+	}
+
+	fmt.Printf("✅ 已登记 %s -> %s\n", name, goRoot)
+	if goVersion != "" {
+		fmt.Printf("   • 版本: %s\n", goVersion)
+	}
+	if goos != "" {
+		fmt.Printf("   • 平台: %s/%s\n", goos, goarch)
+	}
+	fmt.Printf("💡 使用 'go-version-switch -use %s' 切换到该版本\n", name)
+	return nil
+}
+
+// isManagedPath 判断目录是否位于 data/go-version 下（由本工具安装）
+func isManagedPath(baseDir, path string) bool {
+	return isWithinDir(filepath.Join(baseDir, "go-version"), filepath.Clean(path))
+}
+
+// detectGoRootVersion 从 VERSION 文件读取 Go 版本号
+func detectGoRootVersion(goRoot string) (string, error) {
+	f, err := os.Open(filepath.Join(goRoot, "VERSION"))
+	if err != nil {
+		return "", fmt.Errorf("读取 VERSION 文件失败: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if !scanner.Scan() {
+		return "", fmt.Errorf("VERSION 文件为空")
+	}
+	line := strings.TrimSpace(scanner.Text())
+
+	// 正式版本为 go1.21.5，开发版本为 devel go1.22-abcdef ...
+	if m := regexp.MustCompile(`go(\d+\.\d+(?:\.\d+)?(?:(?:rc|beta)\d+)?)`).FindStringSubmatch(line); len(m) >= 2 {
+		return m[1], nil
+	}
+	return line, nil
+}
+
+// detectBinaryPlatform 解析可执行文件头，返回其 GOOS 和 GOARCH
+func detectBinaryPlatform(path string) (string, string, error) {
+	if f, err := pe.Open(path); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case pe.IMAGE_FILE_MACHINE_I386:
+			return "windows", "386", nil
+		case pe.IMAGE_FILE_MACHINE_AMD64:
+			return "windows", "amd64", nil
+		case pe.IMAGE_FILE_MACHINE_ARMNT:
+			return "windows", "arm", nil
+		case pe.IMAGE_FILE_MACHINE_ARM64:
+			return "windows", "arm64", nil
+		}
+		return "windows", "", fmt.Errorf("未知的 PE 机器类型: %#x", f.Machine)
+	}
+
+	if f, err := elf.Open(path); err == nil {
+		defer f.Close()
+		goos := "linux"
+		if f.OSABI == elf.ELFOSABI_FREEBSD {
+			goos = "freebsd"
+		}
+		switch f.Machine {
+		case elf.EM_386:
+			return goos, "386", nil
+		case elf.EM_X86_64:
+			return goos, "amd64", nil
+		case elf.EM_ARM:
+			return goos, "arm", nil
+		case elf.EM_AARCH64:
+			return goos, "arm64", nil
+		}
+		return goos, "", fmt.Errorf("未知的 ELF 机器类型: %v", f.Machine)
+	}
+
+	if f, err := macho.Open(path); err == nil {
+		defer f.Close()
+		switch f.Cpu {
+		case macho.Cpu386:
+			return "darwin", "386", nil
+		case macho.CpuAmd64:
+			return "darwin", "amd64", nil
+		case macho.CpuArm:
+			return "darwin", "arm", nil
+		case macho.CpuArm64:
+			return "darwin", "arm64", nil
+		}
+		return "darwin", "", fmt.Errorf("未知的 Mach-O CPU 类型: %v", f.Cpu)
+	}
+
+	return "", "", fmt.Errorf("无法识别的可执行文件格式: %s", path)
+}
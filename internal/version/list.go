@@ -9,6 +9,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"go-version-switch/internal/config"
 )
 
 // VersionList 版本列表信息
@@ -81,13 +83,19 @@ func GetVersionList(baseDir string, forceUpdate bool) (*VersionList, error) {
 	}
 
 	// 如果不需要更新，尝试从缓存加载
+	var staleVersions []*GoRelease
 	if !needUpdate {
 		versions, err := LoadVersionsCache(cacheFile)
-		if err == nil {
+		if err == nil && hasNonWindowsReleases(versions) {
 			list.Versions = versions
 			list.LastUpdateTime = getFileModTime(cacheFile)
 			return list, nil
 		}
+		if err == nil {
+			// 旧版本只缓存了 Windows 的安装包，无法安装其他系统的版本
+			fmt.Println("💡 版本缓存中只有 Windows 的安装包，重新获取版本列表")
+			staleVersions = versions
+		}
 		// 如果加载缓存失败，需要更新
 		needUpdate = true
 	}
@@ -96,16 +104,21 @@ func GetVersionList(baseDir string, forceUpdate bool) (*VersionList, error) {
 	if needUpdate {
 		fmt.Println("正在从官网获取版本列表...")
 		versions, err := FetchVersions()
-		if err != nil {
-			return nil, fmt.Errorf("获取版本列表失败: %v", err)
-		}
-
-		list.Versions = versions
-		list.LastUpdateTime = time.Now()
+		switch {
+		case err == nil:
+			list.Versions = versions
+			list.LastUpdateTime = time.Now()
 
-		// 保存到缓存
-		if err := SaveVersionsCache(versions, cacheFile); err != nil {
-			fmt.Printf("警告: 保存版本缓存失败: %v\n", err)
+			// 保存到缓存
+			if err := SaveVersionsCache(versions, cacheFile); err != nil {
+				fmt.Printf("警告: 保存版本缓存失败: %v\n", err)
+			}
+		case staleVersions != nil:
+			fmt.Printf("警告: %v，继续使用只含 Windows 安装包的缓存\n", err)
+			list.Versions = staleVersions
+			list.LastUpdateTime = getFileModTime(cacheFile)
+		default:
+			return nil, fmt.Errorf("获取版本列表失败: %v", err)
 		}
 	}
 
@@ -130,6 +143,17 @@ func GetVersionList(baseDir string, forceUpdate bool) (*VersionList, error) {
 	return list, nil
 }
 
+// hasNonWindowsReleases 判断版本列表是否包含 Linux/macOS 的安装包
+func hasNonWindowsReleases(versions []*GoRelease) bool {
+	for _, v := range versions {
+		// 不使用 normalizeOS，空的 OS 字段会被当作当前系统
+		if goos := strings.ToLower(strings.TrimSpace(v.OS)); goos == "linux" || goos == "macos" || goos == "darwin" {
+			return true
+		}
+	}
+	return false
+}
+
 // PrintVersionList 打印版本列表
 func (l *VersionList) PrintVersionList() {
 	fmt.Println(strings.Repeat("=", 80))
@@ -186,7 +210,7 @@ func (l *VersionList) PrintVersionList() {
 		icon := "🪟"
 		if os == "Linux" {
 			icon = "🐧"
-		} else if os == "Darwin" {
+		} else if os == "Darwin" || os == "macOS" {
 			icon = "🍎"
 		}
 		fmt.Printf("   %s %s: %d 个版本\n", icon, os, count)
@@ -276,7 +300,7 @@ func (l *VersionList) PrintVersionList() {
 			osIcon := "🪟"
 			if v.OS == "Linux" {
 				osIcon = "🐧"
-			} else if v.OS == "Darwin" {
+			} else if v.OS == "Darwin" || v.OS == "macOS" {
 				osIcon = "🍎"
 			}
 
@@ -345,8 +369,7 @@ func shouldUpdateVersions() bool {
 
 // getVersionCachePath 获取版本缓存文件路径
 func getVersionCachePath() string {
-	execPath, _ := os.Executable()
-	return filepath.Join(filepath.Dir(execPath), "data", "config", "versions.json")
+	return filepath.Join(config.DataDir(), "config", "versions.json")
 }
 
 // saveVersionListCache 保存版本列表到缓存
@@ -0,0 +1,173 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	lockRetryInterval = 200 * time.Millisecond
+	staleLockAge      = 5 * time.Minute  // 超过该时间没有刷新的锁视为残留（持有进程已退出或 PID 已被复用）
+	dataLockTimeout   = 10 * time.Second // 等待其他进程释放数据目录锁的时间
+	dataLockName      = "go-version-switch.lock"
+	takeoverSuffix    = ".takeover"
+	takeoverTimeout   = 30 * time.Second // 接管残留锁的操作只需要很短时间，超过后视为接管者已退出
+)
+
+// lockHeartbeatInterval 持有锁期间刷新锁文件修改时间的间隔，需远小于 staleLockAge
+var lockHeartbeatInterval = time.Minute
+
+// lockInfo 锁文件中记录的持有者信息
+type lockInfo struct {
+	PID     int    `json:"pid"`
+	Host    string `json:"host"`
+	Time    string `json:"time"`
+	Command string `json:"command,omitempty"` // 持有锁的命令
+}
+
+// fileLock 基于独占创建文件的跨进程锁，在网络共享目录上同样可用。
+// 持有期间定期刷新锁文件的修改时间，等待者据此判断持有者是否仍在运行
+type fileLock struct {
+	path string
+	stop chan struct{}
+	done chan struct{}
+}
+
+// LockDataDir 获取数据目录锁，安装、切换、回滚、清理等修改数据目录的命令需要持有，
+// 返回的函数用于释放锁
+func LockDataDir(baseDir, command string) (func(), error) {
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建数据目录失败: %v", err)
+	}
+	lock, err := acquireFileLock(filepath.Join(baseDir, dataLockName), command, dataLockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("🔒 数据目录正被其他进程使用，请等待其完成后重试\n   %v", err)
+	}
+	return func() {
+		if err := lock.Release(); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+		}
+	}, nil
+}
+
+// acquireFileLock 获取文件锁，被占用时等待直到超时，command 记录持有锁的操作
+func acquireFileLock(path, command string, timeout time.Duration) (*fileLock, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			host, _ := os.Hostname()
+			info := lockInfo{
+				PID:     os.Getpid(),
+				Host:    host,
+				Time:    time.Now().Format(time.RFC3339),
+				Command: command,
+			}
+			data, _ := json.Marshal(info)
+			_, werr := f.Write(data)
+			f.Close()
+			if werr != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("写入锁文件失败: %v", werr)
+			}
+			lock := &fileLock{path: path, stop: make(chan struct{}), done: make(chan struct{})}
+			go lock.heartbeat()
+			return lock, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("创建锁文件失败: %v", err)
+		}
+
+		holder, stale, content := readLockInfo(path)
+		if stale && removeStaleLock(path, content) {
+			fmt.Printf("⚠️ 清理残留的锁文件: %s\n", path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("等待锁超时: %s 被 %s 持有", path, holder)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// readLockInfo 读取锁持有者描述和锁文件内容，并判断锁是否已过期：
+// 同一台机器上持有进程已退出时立即过期；持有者会定期刷新锁文件，
+// 超过 staleLockAge 没有刷新时说明持有者已不在（包括 PID 被其他进程复用），也视为过期
+func readLockInfo(path string) (string, bool, []byte) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		// 锁已被释放，下一轮重试即可
+		return "未知进程", false, nil
+	}
+
+	var info lockInfo
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &info) != nil {
+		return "未知进程", time.Since(stat.ModTime()) > staleLockAge, data
+	}
+
+	holder := fmt.Sprintf("%s 上的进程 PID %d (开始于 %s)", info.Host, info.PID, info.Time)
+	if info.Command != "" {
+		holder = fmt.Sprintf("%s 上的进程 PID %d (命令: %s, 开始于 %s)", info.Host, info.PID, info.Command, info.Time)
+	}
+	age := time.Since(stat.ModTime())
+	if host, _ := os.Hostname(); host != "" && host == info.Host && info.PID > 0 && info.PID != os.Getpid() {
+		if !processAlive(info.PID) {
+			return holder, true, data
+		}
+	}
+	return holder, age > staleLockAge, data
+}
+
+// removeStaleLock 删除过期的锁文件。多个等待者可能同时判断锁已过期，
+// 通过独占创建接管文件保证同一时间只有一个进程删除，并确认内容仍是判断时读取的内容，
+// 避免删除其他等待者刚刚创建的新锁
+func removeStaleLock(path string, stale []byte) bool {
+	takeover := path + takeoverSuffix
+	f, err := os.OpenFile(takeover, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		// 其他进程正在接管，接管者中途退出时清理其残留的接管文件
+		if info, err := os.Stat(takeover); err == nil && time.Since(info.ModTime()) > takeoverTimeout {
+			os.Remove(takeover)
+		}
+		return false
+	}
+	f.Close()
+	defer os.Remove(takeover)
+
+	current, err := os.ReadFile(path)
+	if err != nil || !bytes.Equal(current, stale) {
+		return false
+	}
+	return os.Remove(path) == nil
+}
+
+// heartbeat 定期刷新锁文件的修改时间，直到锁被释放
+func (l *fileLock) heartbeat() {
+	defer close(l.done)
+	ticker := time.NewTicker(lockHeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// Release 释放文件锁
+func (l *fileLock) Release() error {
+	close(l.stop)
+	<-l.done
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("释放锁文件失败: %v", err)
+	}
+	return nil
+}
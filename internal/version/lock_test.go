@@ -0,0 +1,157 @@
+package version
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeLockFile 写入指定持有者的锁文件，heartbeat 为持有者最后一次刷新锁的时间
+func writeLockFile(t *testing.T, path string, info lockInfo, heartbeat time.Time) {
+	t.Helper()
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, heartbeat, heartbeat); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStaleLockTakeoverIsExclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataLockName)
+	old := time.Now().Add(-2 * staleLockAge)
+	writeLockFile(t, path, lockInfo{PID: 1, Host: "other-host", Time: old.Format(time.RFC3339)}, old)
+
+	// 所有等待者同时发现锁已过期，任意时刻只能有一个持有锁
+	var holders, maxHolders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := acquireFileLock(path, "test", 10*time.Second)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := atomic.AddInt32(&holders, 1)
+			for {
+				max := atomic.LoadInt32(&maxHolders)
+				if n <= max || atomic.CompareAndSwapInt32(&maxHolders, max, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&holders, -1)
+			if err := lock.Release(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxHolders != 1 {
+		t.Fatalf("同时持有锁的进程数为 %d", maxHolders)
+	}
+	if _, err := os.Stat(path + takeoverSuffix); !os.IsNotExist(err) {
+		t.Fatalf("接管文件未清理: %v", err)
+	}
+}
+
+func TestRemoveStaleLockKeepsNewLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), dataLockName)
+	old := time.Now().Add(-2 * staleLockAge)
+	writeLockFile(t, path, lockInfo{PID: 1, Host: "other-host", Time: old.Format(time.RFC3339)}, old)
+	_, stale, content := readLockInfo(path)
+	if !stale {
+		t.Fatal("旧锁应视为过期")
+	}
+
+	// 判断过期之后，另一个等待者已删除旧锁并创建了新锁
+	writeLockFile(t, path, lockInfo{PID: 2, Host: "other-host", Time: time.Now().Format(time.RFC3339)}, time.Now())
+	if removeStaleLock(path, content) {
+		t.Fatal("不应删除其他等待者新建的锁")
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("新锁被删除: %v", err)
+	}
+
+	// 其他进程正在接管时不删除
+	writeLockFile(t, path, lockInfo{PID: 1, Host: "other-host", Time: old.Format(time.RFC3339)}, old)
+	_, _, content = readLockInfo(path)
+	if err := os.WriteFile(path+takeoverSuffix, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if removeStaleLock(path, content) {
+		t.Fatal("接管文件存在时不应删除锁")
+	}
+	os.Remove(path + takeoverSuffix)
+	if !removeStaleLock(path, content) {
+		t.Fatal("内容未变时应删除过期的锁")
+	}
+}
+
+func TestReadLockInfoStaleness(t *testing.T) {
+	host, _ := os.Hostname()
+	now := time.Now()
+	old := now.Add(-2 * staleLockAge)
+
+	tests := []struct {
+		name      string
+		info      lockInfo
+		heartbeat time.Time
+		stale     bool
+	}{
+		{"其他机器上刷新中的锁", lockInfo{PID: 1, Host: "other-host", Time: old.Format(time.RFC3339)}, now, false},
+		{"其他机器上停止刷新的锁", lockInfo{PID: 1, Host: "other-host", Time: old.Format(time.RFC3339)}, old, true},
+		{"本机运行中的进程", lockInfo{PID: os.Getppid(), Host: host, Time: now.Format(time.RFC3339)}, now, false},
+		// 长时间运行的命令持续刷新锁，不因开始时间早而过期
+		{"本机长时间运行的进程", lockInfo{PID: os.Getppid(), Host: host, Time: old.Format(time.RFC3339)}, now, false},
+		// PID 仍在运行但锁已停止刷新，说明 PID 被其他进程复用
+		{"本机 PID 被复用", lockInfo{PID: os.Getppid(), Host: host, Time: old.Format(time.RFC3339)}, old, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), dataLockName)
+			writeLockFile(t, path, tt.info, tt.heartbeat)
+			if _, stale, _ := readLockInfo(path); stale != tt.stale {
+				t.Fatalf("stale = %v，期望 %v", stale, tt.stale)
+			}
+		})
+	}
+}
+
+func TestHeldLockIsNotTakenOver(t *testing.T) {
+	old := lockHeartbeatInterval
+	lockHeartbeatInterval = 10 * time.Millisecond
+	t.Cleanup(func() { lockHeartbeatInterval = old })
+
+	path := filepath.Join(t.TempDir(), dataLockName)
+	lock, err := acquireFileLock(path, "长时间运行的命令", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Release()
+
+	// 锁已持有很久，持有者仍在运行并刷新锁文件
+	started := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(path, started, started); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if _, stale, _ := readLockInfo(path); stale {
+		t.Fatal("持有者仍在运行时锁不应过期")
+	}
+	if _, err := acquireFileLock(path, "test", 300*time.Millisecond); err == nil {
+		t.Fatal("不应接管仍被持有的锁")
+	}
+}
@@ -0,0 +1,560 @@
+package version
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Manifest 安装时记录的文件清单，用于检测被修改、删除或新增的文件
+type Manifest struct {
+	Version       string                  `json:"version"`        // 版本号
+	OS            string                  `json:"os"`             // 操作系统
+	Arch          string                  `json:"arch"`           // 架构
+	Archive       string                  `json:"archive"`        // 安装时使用的安装包路径
+	ArchiveSHA256 string                  `json:"archive_sha256"` // 安装包的 SHA256，用于修复时查找安装包
+	Prefix        string                  `json:"prefix"`         // 安装包中的顶层目录
+	Created       string                  `json:"created"`        // 生成时间
+	Files         map[string]ManifestFile `json:"files"`          // 相对路径 -> 文件信息
+}
+
+// ManifestFile 清单中的单个文件
+type ManifestFile struct {
+	Size   int64  `json:"size,omitempty"`
+	SHA256 string `json:"sha256,omitempty"`
+	Link   string `json:"link,omitempty"` // 符号链接目标
+}
+
+// VerifyOptions 校验选项
+type VerifyOptions struct {
+	Version string // 版本号，为空时校验所有已安装版本
+	OS      string // 目标操作系统，为空时使用当前系统
+	Arch    string // 架构，为空时使用当前系统架构
+	Repair  bool   // 从安装包中恢复被修改或删除的文件
+}
+
+// verifyResult 单个版本的校验结果
+type verifyResult struct {
+	Modified []string
+	Missing  []string
+	Extra    []string
+}
+
+func (r *verifyResult) ok() bool {
+	return len(r.Modified) == 0 && len(r.Missing) == 0 && len(r.Extra) == 0
+}
+
+// maxListedFiles 每类问题最多列出的文件数
+const maxListedFiles = 20
+
+// manifestPathFor 返回安装目录对应的清单文件 data/manifest/<目录名>.json
+func manifestPathFor(versionDir string) string {
+	baseDir := filepath.Dir(filepath.Dir(versionDir))
+	return filepath.Join(baseDir, "manifest", filepath.Base(versionDir)+".json")
+}
+
+// buildManifest 计算目录中所有文件的哈希
+func buildManifest(root string) (map[string]ManifestFile, error) {
+	files := make(map[string]ManifestFile)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		entry, err := manifestEntry(path, d)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = entry
+		return nil
+	})
+	return files, err
+}
+
+// manifestEntry 计算单个文件的清单信息
+func manifestEntry(path string, d fs.DirEntry) (ManifestFile, error) {
+	if d.Type()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return ManifestFile{}, err
+		}
+		return ManifestFile{Link: filepath.ToSlash(target)}, nil
+	}
+	info, err := d.Info()
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	sha, err := fileSHA256(path)
+	if err != nil {
+		return ManifestFile{}, err
+	}
+	return ManifestFile{Size: info.Size(), SHA256: sha}, nil
+}
+
+// newInstallManifest 为临时目录生成安装到 targetDir 的清单，安装包路径为空表示不是从安装包安装。
+// 清单在替换目标目录成功后再由 saveInstallManifest 保存
+func newInstallManifest(staging, targetDir, archivePath, prefix string) (*Manifest, error) {
+	fmt.Println("🧾 正在生成文件清单...")
+	files, err := buildManifest(staging)
+	if err != nil {
+		return nil, fmt.Errorf("生成文件清单失败: %v", err)
+	}
+
+	version, arch, goos, _ := parseVersionDirName(filepath.Base(targetDir))
+	m := &Manifest{
+		Version: version,
+		OS:      goos,
+		Arch:    arch,
+		Prefix:  prefix,
+		Created: time.Now().Format("2006-01-02 15:04:05"),
+		Files:   files,
+	}
+	if archivePath != "" {
+		if abs, err := filepath.Abs(archivePath); err == nil {
+			m.Archive = abs
+		}
+		if m.ArchiveSHA256, err = fileSHA256(archivePath); err != nil {
+			return nil, fmt.Errorf("计算安装包校验和失败: %v", err)
+		}
+	}
+	return m, nil
+}
+
+// saveInstallManifest 保存安装目录的清单，清单只用于 -verify，m 为空或保存失败不影响安装
+func saveInstallManifest(targetDir string, m *Manifest) {
+	if m == nil {
+		return
+	}
+	if err := saveManifest(manifestPathFor(targetDir), m); err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+}
+
+// saveManifest 保存清单文件
+func saveManifest(path string, m *Manifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建清单目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return fmt.Errorf("序列化清单失败: %v", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("写入清单失败: %v", err)
+	}
+	return nil
+}
+
+// loadManifest 读取清单文件
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("解析清单失败: %v", err)
+	}
+	return &m, nil
+}
+
+// VerifyVersions 按安装清单校验已安装版本，可选择从安装包修复
+func VerifyVersions(baseDir string, opts VerifyOptions) error {
+	dirs, err := verifyTargets(baseDir, opts)
+	if err != nil {
+		return err
+	}
+	if len(dirs) == 0 {
+		fmt.Println("⚠️ 没有已安装的版本")
+		return nil
+	}
+
+	failed := 0
+	for _, dir := range dirs {
+		fmt.Printf("\n🔍 正在校验: %s\n", dir)
+		m, err := loadManifest(manifestPathFor(dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				fmt.Println("💡 没有文件清单（可能安装于清单功能之前），重新安装后即可校验")
+				continue
+			}
+			fmt.Printf("❌ %v\n", err)
+			failed++
+			continue
+		}
+
+		result, err := verifyAgainstManifest(dir, m)
+		if err != nil {
+			fmt.Printf("❌ 校验失败: %v\n", err)
+			failed++
+			continue
+		}
+		if result.ok() {
+			fmt.Printf("✅ %d 个文件全部一致\n", len(m.Files))
+			continue
+		}
+		printVerifyResult(result)
+
+		if !opts.Repair {
+			failed++
+			continue
+		}
+		if len(result.Modified)+len(result.Missing) == 0 {
+			fmt.Println("💡 只有新增的文件，无需修复")
+			continue
+		}
+		if err := repairVersion(baseDir, dir, m, result); err != nil {
+			fmt.Printf("❌ 修复失败: %v\n", err)
+			failed++
+			continue
+		}
+	}
+
+	if failed > 0 {
+		hint := ""
+		if !opts.Repair {
+			hint = "，可使用 -repair 从安装包恢复"
+		}
+		return fmt.Errorf("%d 个版本校验未通过%s", failed, hint)
+	}
+	return nil
+}
+
+// verifyTargets 返回需要校验的安装目录
+func verifyTargets(baseDir string, opts VerifyOptions) ([]string, error) {
+	versionDir := filepath.Join(baseDir, "go-version")
+	if opts.Version != "" {
+		if opts.Arch == "" {
+			opts.Arch = runtime.GOARCH
+		}
+		arch := normalizeArch(opts.Arch)
+		if arch == "" {
+			return nil, fmt.Errorf("不支持的架构: %s", opts.Arch)
+		}
+		goos := normalizeOS(opts.OS)
+		if goos == "" {
+			return nil, fmt.Errorf("不支持的操作系统: %s (可选: windows/linux/darwin)", opts.OS)
+		}
+		dir := filepath.Join(versionDir, versionDirName(opts.Version, goos, arch))
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("版本 %s (%s) 未安装", opts.Version, arch)
+		}
+		return []string{dir}, nil
+	}
+
+	entries, err := os.ReadDir(versionDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取版本目录失败: %v", err)
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if _, _, _, ok := parseVersionDirName(entry.Name()); ok && entry.IsDir() {
+			dirs = append(dirs, filepath.Join(versionDir, entry.Name()))
+		}
+	}
+	return dirs, nil
+}
+
+// verifyAgainstManifest 对比目录内容与清单
+func verifyAgainstManifest(dir string, m *Manifest) (*verifyResult, error) {
+	result := &verifyResult{}
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		expected, ok := m.Files[rel]
+		if !ok {
+			result.Extra = append(result.Extra, rel)
+			return nil
+		}
+		seen[rel] = true
+
+		actual, err := manifestEntry(path, d)
+		if err != nil {
+			// 无法读取（如被杀毒软件锁定）视为已修改
+			result.Modified = append(result.Modified, rel)
+			return nil
+		}
+		if actual != expected {
+			result.Modified = append(result.Modified, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for rel := range m.Files {
+		if !seen[rel] {
+			result.Missing = append(result.Missing, rel)
+		}
+	}
+	sort.Strings(result.Modified)
+	sort.Strings(result.Missing)
+	sort.Strings(result.Extra)
+	return result, nil
+}
+
+// printVerifyResult 输出校验结果
+func printVerifyResult(r *verifyResult) {
+	groups := []struct {
+		title string
+		files []string
+	}{
+		{"✏️  被修改", r.Modified},
+		{"❓ 缺失", r.Missing},
+		{"➕ 新增", r.Extra},
+	}
+	for _, g := range groups {
+		if len(g.files) == 0 {
+			continue
+		}
+		fmt.Printf("%s: %d 个文件\n", g.title, len(g.files))
+		for i, f := range g.files {
+			if i == maxListedFiles {
+				fmt.Printf("   ... 以及其他 %d 个文件\n", len(g.files)-maxListedFiles)
+				break
+			}
+			fmt.Printf("   • %s\n", f)
+		}
+	}
+}
+
+// repairVersion 从安装包中重新解压被修改或缺失的文件
+func repairVersion(baseDir, dir string, m *Manifest, result *verifyResult) error {
+	archive, err := locateManifestArchive(baseDir, m)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📦 使用安装包修复: %s\n", archive)
+
+	want := make(map[string]bool)
+	for _, rel := range append(append([]string{}, result.Modified...), result.Missing...) {
+		want[rel] = true
+	}
+	restored, err := extractArchiveFiles(archive, dir, m.Prefix, want)
+	if err != nil {
+		return err
+	}
+	if err := fixToolchainPermissions(dir, m.OS); err != nil {
+		return fmt.Errorf("设置可执行权限失败: %v", err)
+	}
+
+	// 修复后再次校验
+	after, err := verifyAgainstManifest(dir, m)
+	if err != nil {
+		return err
+	}
+	if len(after.Modified)+len(after.Missing) > 0 {
+		printVerifyResult(after)
+		return fmt.Errorf("仍有 %d 个文件无法恢复", len(after.Modified)+len(after.Missing))
+	}
+	fmt.Printf("✅ 已恢复 %d 个文件\n", restored)
+	return nil
+}
+
+// locateManifestArchive 按安装包路径、共享缓存、下载目录和官方版本列表查找安装时使用的安装包
+func locateManifestArchive(baseDir string, m *Manifest) (string, error) {
+	if m.ArchiveSHA256 == "" {
+		return "", fmt.Errorf("该版本不是从安装包安装的，无法自动修复，请重新安装")
+	}
+
+	if m.Archive != "" && verifyChecksum(m.Archive, m.ArchiveSHA256) == nil {
+		return m.Archive, nil
+	}
+
+	cache := openArchiveCache(baseDir)
+	if cached, ok := cache.Lookup(m.ArchiveSHA256); ok {
+		return cached, nil
+	}
+
+	downloadDir := filepath.Join(baseDir, "down")
+	for _, name := range versionArchiveNames(m.Version, m.OS, m.Arch) {
+		path := filepath.Join(downloadDir, name)
+		if verifyChecksum(path, m.ArchiveSHA256) == nil {
+			return path, nil
+		}
+	}
+
+	// 官方安装包可以重新下载
+	release, err := findTargetRelease(baseDir, InstallOptions{Version: m.Version, OS: m.OS, Arch: m.Arch})
+	if err != nil || !strings.EqualFold(release.SHA256, m.ArchiveSHA256) {
+		return "", fmt.Errorf("找不到原始安装包 (SHA256: %s)，请重新安装该版本", m.ArchiveSHA256)
+	}
+
+	fmt.Printf("📥 本地没有原始安装包，重新下载: %s\n", release.DownloadURL)
+	if cache.Writable() {
+		return cache.Fetch(release.DownloadURL, m.ArchiveSHA256)
+	}
+
+	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return "", fmt.Errorf("📁 创建下载目录失败: %v", err)
+	}
+	path := filepath.Join(downloadDir, archiveFileName(m.Version, m.OS, normalizeArch(m.Arch)))
+	if err := downloadWithProgress(release.DownloadURL, path); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("❌ 下载失败: %v", err)
+	}
+	if err := verifyChecksum(path, m.ArchiveSHA256); err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("❌ %v", err)
+	}
+	return path, nil
+}
+
+// extractArchiveFiles 只解压指定的文件（相对于安装目录的路径），返回恢复的文件数
+func extractArchiveFiles(src, dest, prefix string, want map[string]bool) (int, error) {
+	format, err := detectArchiveFormat(src)
+	if err != nil {
+		return 0, err
+	}
+
+	restored := 0
+	// restore 校验路径后替换单个文件，符号链接目标为空表示普通文件
+	restore := func(name string, linkTarget string, open func() (io.ReadCloser, error), perm os.FileMode, mtime time.Time) error {
+		fpath, err := archiveEntryPath(dest, name, prefix)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dest, fpath)
+		if err != nil || !want[filepath.ToSlash(rel)] {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			return err
+		}
+		os.Remove(fpath)
+
+		if linkTarget != "" {
+			if err := checkSymlinkTarget(dest, fpath, linkTarget, name); err != nil {
+				return err
+			}
+			if err := os.Symlink(filepath.FromSlash(linkTarget), fpath); err != nil {
+				return err
+			}
+			restored++
+			return nil
+		}
+
+		if perm == 0 {
+			perm = 0644
+		}
+		rc, err := open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		out, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, io.LimitReader(rc, maxExtractSize))
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err == nil {
+			err = setFileMeta(fpath, perm, mtime)
+		}
+		if err != nil {
+			return fmt.Errorf("恢复 %s 失败: %v", name, err)
+		}
+		restored++
+		return nil
+	}
+
+	if format == formatZip {
+		r, err := zip.OpenReader(src)
+		if err != nil {
+			return 0, fmt.Errorf("打开zip文件失败: %v", err)
+		}
+		defer r.Close()
+		for _, f := range r.File {
+			f := f
+			mode := f.Mode()
+			var target string
+			switch {
+			case mode&os.ModeSymlink != 0:
+				if target, err = readZipSymlink(f); err != nil {
+					return restored, err
+				}
+			case !mode.IsRegular():
+				continue
+			}
+			open := func() (io.ReadCloser, error) { return f.Open() }
+			if err := restore(f.Name, target, open, mode.Perm(), zipModTime(f)); err != nil {
+				return restored, err
+			}
+		}
+		return restored, nil
+	}
+
+	file, err := os.Open(src)
+	if err != nil {
+		return 0, fmt.Errorf("打开tar.gz文件失败: %v", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return 0, fmt.Errorf("读取gzip数据失败: %v", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return restored, fmt.Errorf("读取tar数据失败: %v", err)
+		}
+		var target string
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+		case tar.TypeSymlink:
+			target = hdr.Linkname
+		default:
+			continue
+		}
+		open := func() (io.ReadCloser, error) { return io.NopCloser(tr), nil }
+		if err := restore(hdr.Name, target, open, hdr.FileInfo().Mode().Perm(), hdr.ModTime); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
+
+// removeManifest 删除安装目录对应的清单
+func removeManifest(versionDir string) {
+	path := manifestPathFor(versionDir)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("⚠️ 删除文件清单失败: %v\n", err)
+	}
+}
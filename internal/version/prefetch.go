@@ -0,0 +1,161 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// PrefetchOptions 预下载选项
+type PrefetchOptions struct {
+	Versions []string // 版本号列表
+	Archs    []string // 架构列表
+	OS       string   // 目标操作系统，为空时使用当前系统
+	Dir      string   // 保存目录，为空时使用 data/down
+}
+
+// PrefetchEntry 离线包索引中的单个条目
+type PrefetchEntry struct {
+	File    string `json:"file"`    // 文件名
+	Version string `json:"version"` // 版本号
+	OS      string `json:"os"`      // 操作系统
+	Arch    string `json:"arch"`    // 架构
+	Size    int64  `json:"size"`    // 文件大小（字节）
+	SHA256  string `json:"sha256"`  // SHA256校验和
+	URL     string `json:"url"`     // 下载地址
+}
+
+// PrefetchIndex 离线包索引
+type PrefetchIndex struct {
+	Generated string           `json:"generated"` // 生成时间
+	Entries   []*PrefetchEntry `json:"entries"`   // 安装包列表
+}
+
+const prefetchIndexFile = "index.json"
+
+// PrefetchVersions 下载并校验安装包，但不解压也不修改环境变量
+func PrefetchVersions(baseDir string, opts PrefetchOptions) error {
+	if len(opts.Versions) == 0 {
+		return fmt.Errorf("未指定要下载的版本")
+	}
+	if len(opts.Archs) == 0 {
+		return fmt.Errorf("未指定要下载的架构")
+	}
+
+	downloadDir := opts.Dir
+	if downloadDir == "" {
+		downloadDir = filepath.Join(baseDir, "down")
+	}
+	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return fmt.Errorf("📁 创建下载目录失败: %v", err)
+	}
+
+	// 先解析所有目标，避免下载到一半才发现版本不存在
+	var releases []*GoRelease
+	for _, v := range opts.Versions {
+		for _, a := range opts.Archs {
+			release, err := findTargetRelease(baseDir, InstallOptions{Version: v, OS: opts.OS, Arch: a})
+			if err != nil {
+				return err
+			}
+			releases = append(releases, release)
+		}
+	}
+
+	index := loadPrefetchIndex(downloadDir)
+	fmt.Printf("📂 下载目录: %s\n", downloadDir)
+	fmt.Printf("📦 共 %d 个安装包待下载\n", len(releases))
+
+	for i, release := range releases {
+		fileName := archiveFileName(release.Version, normalizeOS(release.OS), normalizeArch(release.Arch))
+		filePath := filepath.Join(downloadDir, fileName)
+		fmt.Printf("\n[%d/%d] Go %s (%s)\n", i+1, len(releases), release.Version, release.Arch)
+
+		if err := prefetchRelease(release, filePath); err != nil {
+			return err
+		}
+
+		info, err := os.Stat(filePath)
+		if err != nil {
+			return fmt.Errorf("读取文件信息失败: %v", err)
+		}
+		index.put(&PrefetchEntry{
+			File:    fileName,
+			Version: release.Version,
+			OS:      normalizeOS(release.OS),
+			Arch:    strings.ToLower(normalizeArch(release.Arch)),
+			Size:    info.Size(),
+			SHA256:  strings.ToLower(release.SHA256),
+			URL:     release.DownloadURL,
+		})
+	}
+
+	index.Generated = time.Now().Format("2006-01-02 15:04:05")
+	indexPath := filepath.Join(downloadDir, prefetchIndexFile)
+	data, err := json.MarshalIndent(index, "", "    ")
+	if err != nil {
+		return fmt.Errorf("序列化索引失败: %v", err)
+	}
+	if err := os.WriteFile(indexPath, data, 0644); err != nil {
+		return fmt.Errorf("写入索引文件失败: %v", err)
+	}
+
+	fmt.Printf("\n✅ 下载完成，索引文件: %s\n", indexPath)
+	return nil
+}
+
+// prefetchRelease 下载单个安装包，已存在且校验通过时跳过
+func prefetchRelease(release *GoRelease, filePath string) error {
+	if _, err := os.Stat(filePath); err == nil {
+		fmt.Printf("💡 发现已下载的文件: %s\n", filePath)
+		if err := verifyChecksum(filePath, release.SHA256); err == nil {
+			fmt.Printf("✅ 文件验证成功，跳过下载\n")
+			return nil
+		}
+		fmt.Printf("⚠️ 文件验证失败，重新下载...\n")
+		if err := os.Remove(filePath); err != nil {
+			return fmt.Errorf("删除损坏的文件失败: %v", err)
+		}
+	}
+
+	fmt.Printf("📥 开始下载: %s\n", release.DownloadURL)
+	if err := downloadWithProgress(release.DownloadURL, filePath); err != nil {
+		return fmt.Errorf("❌ 下载失败: %v", err)
+	}
+
+	fmt.Printf("🔍 正在验证文件完整性...\n")
+	if err := verifyChecksum(filePath, release.SHA256); err != nil {
+		os.Remove(filePath)
+		return fmt.Errorf("❌ %v", err)
+	}
+	fmt.Printf("✅ 文件验证成功\n")
+	return nil
+}
+
+// loadPrefetchIndex 读取已有索引，不存在或损坏时返回空索引
+func loadPrefetchIndex(dir string) *PrefetchIndex {
+	index := &PrefetchIndex{}
+	data, err := os.ReadFile(filepath.Join(dir, prefetchIndexFile))
+	if err != nil {
+		return index
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		fmt.Printf("警告: 解析已有索引失败，将重新生成: %v\n", err)
+		return &PrefetchIndex{}
+	}
+	return index
+}
+
+// put 添加或替换同名条目
+func (idx *PrefetchIndex) put(entry *PrefetchEntry) {
+	for i, e := range idx.Entries {
+		if e.File == entry.File {
+			idx.Entries[i] = entry
+			return
+		}
+	}
+	idx.Entries = append(idx.Entries, entry)
+}
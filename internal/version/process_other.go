@@ -0,0 +1,8 @@
+//go:build !windows && !linux && !darwin && !freebsd
+
+package version
+
+// processAlive 当前平台无法检查进程状态，视为存活，由锁的时间判断是否过期
+func processAlive(pid int) bool {
+	return true
+}
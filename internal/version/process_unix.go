@@ -0,0 +1,11 @@
+//go:build linux || darwin || freebsd
+
+package version
+
+import "syscall"
+
+// processAlive 判断进程是否仍在运行，无权限发送信号时视为存活
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
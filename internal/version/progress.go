@@ -0,0 +1,311 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressUnit 进度计量单位
+type ProgressUnit string
+
+const (
+	UnitBytes ProgressUnit = "bytes" // 字节（下载）
+	UnitFiles ProgressUnit = "files" // 文件数（解压）
+)
+
+// 进度输出模式
+const (
+	ProgressModeAuto  = "auto"  // 根据终端自动选择
+	ProgressModeBar   = "bar"   // 终端进度条
+	ProgressModePlain = "plain" // 逐行输出，适合 CI 日志
+	ProgressModeJSON  = "json"  // JSON 事件，输出到 stderr
+	ProgressModeQuiet = "quiet" // 不输出进度
+)
+
+// ProgressReporter 统一的进度报告接口
+type ProgressReporter interface {
+	// Start 开始一个任务，total 未知时传入 0
+	Start(task string, total int64, unit ProgressUnit)
+	// Add 增加已完成量，可并发调用
+	Add(n int64)
+	// Finish 结束当前任务
+	Finish()
+}
+
+var progressMode = ProgressModeAuto
+
+// SetProgressMode 设置进度输出模式
+func SetProgressMode(mode string) error {
+	switch mode {
+	case "", ProgressModeAuto:
+		progressMode = ProgressModeAuto
+	case ProgressModeBar, ProgressModePlain, ProgressModeJSON, ProgressModeQuiet:
+		progressMode = mode
+	default:
+		return fmt.Errorf("不支持的进度模式: %s (可选: auto/bar/plain/json/quiet)", mode)
+	}
+	return nil
+}
+
+// newProgressReporter 根据当前模式创建进度报告器
+func newProgressReporter() ProgressReporter {
+	mode := progressMode
+	if mode == ProgressModeAuto {
+		mode = detectProgressMode()
+	}
+
+	switch mode {
+	case ProgressModeBar:
+		return &barReporter{bar: NewDefaultProgressBar(), out: os.Stdout}
+	case ProgressModeJSON:
+		return &jsonReporter{enc: json.NewEncoder(os.Stderr)}
+	case ProgressModeQuiet:
+		return silentReporter{}
+	default:
+		return &lineReporter{out: os.Stdout}
+	}
+}
+
+// detectProgressMode 检测标准输出是否为终端
+func detectProgressMode() string {
+	if os.Getenv("CI") != "" {
+		return ProgressModePlain
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return ProgressModePlain
+	}
+	return ProgressModeBar
+}
+
+// unknownTotalInterval 总量未知时（如分块传输的下载）按时间间隔输出已完成量
+var unknownTotalInterval = time.Second
+
+// progressState 各实现共用的计数状态
+type progressState struct {
+	mu        sync.Mutex
+	task      string
+	unit      ProgressUnit
+	total     int64
+	current   int64
+	startTime time.Time
+}
+
+func (s *progressState) start(task string, total int64, unit ProgressUnit) {
+	s.task = task
+	s.total = total
+	s.unit = unit
+	s.current = 0
+	s.startTime = time.Now()
+}
+
+// percent 返回完成比例 (0-1)，总量未知时返回 -1
+func (s *progressState) percent() float64 {
+	if s.total <= 0 {
+		return -1
+	}
+	p := float64(s.current) / float64(s.total)
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
+
+// speed 返回每秒处理量
+func (s *progressState) speed() float64 {
+	elapsed := time.Since(s.startTime).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.current) / elapsed
+}
+
+// eta 计算预计剩余时间
+func (s *progressState) eta() string {
+	speed := s.speed()
+	if speed <= 0 || s.total <= 0 {
+		return "计算中..."
+	}
+	return fmt.Sprintf("%.0fs", float64(s.total-s.current)/speed)
+}
+
+// amount 按单位格式化当前进度
+func (s *progressState) amount() string {
+	if s.unit == UnitBytes {
+		if s.total > 0 {
+			return fmt.Sprintf("%.1fMB/%.1fMB", float64(s.current)/1024/1024, float64(s.total)/1024/1024)
+		}
+		return fmt.Sprintf("%.1fMB", float64(s.current)/1024/1024)
+	}
+	if s.total > 0 {
+		return fmt.Sprintf("%d/%d", s.current, s.total)
+	}
+	return fmt.Sprintf("%d", s.current)
+}
+
+// barReporter 终端进度条，使用 \r 原地刷新
+type barReporter struct {
+	progressState
+	bar        *ProgressBar
+	out        io.Writer
+	lastRender time.Time
+}
+
+func (r *barReporter) Start(task string, total int64, unit ProgressUnit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start(task, total, unit)
+	r.lastRender = time.Time{}
+}
+
+func (r *barReporter) Add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current += n
+	// 限制刷新频率，避免大量小文件时输出过多；总大小未知时同样限制，已完成时立即刷新
+	if time.Since(r.lastRender) < 100*time.Millisecond && (r.total <= 0 || r.current < r.total) {
+		return
+	}
+	r.lastRender = time.Now()
+	r.render()
+}
+
+func (r *barReporter) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.render()
+	fmt.Fprintln(r.out)
+}
+
+func (r *barReporter) render() {
+	percent := r.percent()
+	if percent < 0 {
+		fmt.Fprintf(r.out, "\r⏳ %s: %s", r.task, r.amount())
+		return
+	}
+	if r.unit == UnitBytes {
+		fmt.Fprintf(r.out, "\r⏳ %s: [%s] %.1f%% %.1fMB/s ETA: %s",
+			r.task, r.bar.RenderProgressBar(percent), percent*100, r.speed()/1024/1024, r.eta())
+		return
+	}
+	fmt.Fprintf(r.out, "\r📦 %s: [%s] %s", r.task, r.bar.RenderProgressBar(percent), r.amount())
+}
+
+// lineReporter 逐行输出，每完成 10% 打印一行，总量未知时按时间间隔打印已完成量
+type lineReporter struct {
+	progressState
+	out      io.Writer
+	lastStep int
+	lastLine time.Time
+}
+
+func (r *lineReporter) Start(task string, total int64, unit ProgressUnit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start(task, total, unit)
+	r.lastStep = 0
+	r.lastLine = time.Now()
+	fmt.Fprintf(r.out, "⏳ %s: 开始\n", task)
+}
+
+func (r *lineReporter) Add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current += n
+	percent := r.percent()
+	if percent < 0 {
+		if time.Since(r.lastLine) >= unknownTotalInterval {
+			r.lastLine = time.Now()
+			fmt.Fprintf(r.out, "⏳ %s: %s\n", r.task, r.amount())
+		}
+		return
+	}
+	step := int(percent * 10)
+	if step > r.lastStep && step < 10 {
+		r.lastStep = step
+		fmt.Fprintf(r.out, "⏳ %s: %d%% (%s)\n", r.task, step*10, r.amount())
+	}
+}
+
+func (r *lineReporter) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fmt.Fprintf(r.out, "✅ %s: 完成 (%s, 耗时 %.1fs)\n", r.task, r.amount(), time.Since(r.startTime).Seconds())
+}
+
+// progressEvent JSON 进度事件
+type progressEvent struct {
+	Event   string       `json:"event"` // start/progress/finish
+	Task    string       `json:"task"`
+	Unit    ProgressUnit `json:"unit"`
+	Current int64        `json:"current"`
+	Total   int64        `json:"total"` // 0 表示总量未知
+	Time    string       `json:"time"`
+}
+
+// jsonReporter 输出 JSON 行事件，每变化 1% 输出一次，总量未知时按时间间隔输出已完成量
+type jsonReporter struct {
+	progressState
+	enc         *json.Encoder
+	lastPercent int
+	lastEmit    time.Time
+}
+
+func (r *jsonReporter) Start(task string, total int64, unit ProgressUnit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.start(task, total, unit)
+	r.lastPercent = 0
+	r.emit("start")
+}
+
+func (r *jsonReporter) Add(n int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.current += n
+	if r.percent() < 0 {
+		if time.Since(r.lastEmit) >= unknownTotalInterval {
+			r.emit("progress")
+		}
+		return
+	}
+	percent := int(r.percent() * 100)
+	if percent > r.lastPercent {
+		r.lastPercent = percent
+		r.emit("progress")
+	}
+}
+
+func (r *jsonReporter) Finish() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emit("finish")
+}
+
+func (r *jsonReporter) emit(event string) {
+	total := r.total
+	if total < 0 {
+		total = 0
+	}
+	r.lastEmit = time.Now()
+	_ = r.enc.Encode(progressEvent{
+		Event:   event,
+		Task:    strings.TrimSpace(r.task),
+		Unit:    r.unit,
+		Current: r.current,
+		Total:   total,
+		Time:    r.lastEmit.Format(time.RFC3339),
+	})
+}
+
+// silentReporter 不输出任何进度
+type silentReporter struct{}
+
+func (silentReporter) Start(string, int64, ProgressUnit) {}
+func (silentReporter) Add(int64)                         {}
+func (silentReporter) Finish()                           {}
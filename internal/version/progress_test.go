@@ -0,0 +1,55 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestProgressUnknownTotal(t *testing.T) {
+	old := unknownTotalInterval
+	unknownTotalInterval = 0
+	t.Cleanup(func() { unknownTotalInterval = old })
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := &jsonReporter{enc: json.NewEncoder(&buf)}
+		r.Start("下载 go1.21.5", -1, UnitBytes)
+		r.Add(1024)
+		r.Add(2048)
+		r.Finish()
+
+		var events []progressEvent
+		dec := json.NewDecoder(&buf)
+		for dec.More() {
+			var ev progressEvent
+			if err := dec.Decode(&ev); err != nil {
+				t.Fatal(err)
+			}
+			events = append(events, ev)
+		}
+		if len(events) != 4 {
+			t.Fatalf("事件数 = %d，期望 4: %+v", len(events), events)
+		}
+		for i, want := range []int64{0, 1024, 3072, 3072} {
+			if events[i].Current != want || events[i].Total != 0 {
+				t.Errorf("events[%d] = %+v，期望 current=%d total=0", i, events[i], want)
+			}
+		}
+		if events[1].Event != "progress" || events[2].Event != "progress" {
+			t.Errorf("中间事件应为 progress: %+v", events[1:3])
+		}
+	})
+
+	t.Run("plain", func(t *testing.T) {
+		var buf bytes.Buffer
+		r := &lineReporter{out: &buf}
+		r.Start("下载 go1.21.5", -1, UnitBytes)
+		r.Add(1024 * 1024)
+		r.Finish()
+		if !strings.Contains(buf.String(), "⏳ 下载 go1.21.5: 1.0MB\n") {
+			t.Errorf("未输出已完成量:\n%s", buf.String())
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go-version-switch/internal/config"
+)
+
+// staleStagingAge 超过该时间的临时目录视为中断安装的残留
+const staleStagingAge = time.Hour
+
+// PruneOptions 清理策略，值为 0 或 false 的策略不启用
+type PruneOptions struct {
+	KeepPatches       int  // 每个次版本（如 1.21）保留最新的 N 个补丁版本
+	InstalledArchives bool // 删除已安装版本的安装包
+	ArchiveDays       int  // 删除超过 N 天的安装包
+	KeepBackups       int  // 只保留最新的 N 个环境变量备份
+	DryRun            bool // 只列出将被删除的内容
+}
+
+// pruneItem 待清理的条目
+type pruneItem struct {
+	Path    string
+	Reason  string
+	Size    int64
+	Version string // 版本目录对应的版本号，非版本目录为空
+}
+
+// minorPattern 匹配版本号的次版本部分，如 1.21.5 -> 1.21
+var minorPattern = regexp.MustCompile(`^(\d+\.\d+)`)
+
+// Prune 按策略清理 data/go-version、data/down 和 data/backup_env，
+// 当前使用的版本和固定的版本永远不会被删除
+func Prune(baseDir string, opts PruneOptions) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	var items []pruneItem
+	items = append(items, pruneLeftovers(baseDir)...)
+	if opts.KeepPatches > 0 {
+		items = append(items, pruneVersions(baseDir, cfg, opts.KeepPatches)...)
+	}
+	items = append(items, pruneArchives(baseDir, opts)...)
+	if opts.KeepBackups > 0 {
+		items = append(items, pruneBackups(baseDir, opts.KeepBackups)...)
+	}
+
+	if len(items) == 0 {
+		fmt.Println("✨ 没有需要清理的内容")
+		return nil
+	}
+
+	var total int64
+	for _, item := range items {
+		total += item.Size
+	}
+
+	if opts.DryRun {
+		fmt.Printf("📋 以下 %d 项将被清理 (共 %s):\n", len(items), formatBytes(total))
+		for _, item := range items {
+			fmt.Printf("   • %s (%s) - %s\n", item.Path, formatBytes(item.Size), item.Reason)
+		}
+		fmt.Println("💡 这是预演 (-dry-run)，未删除任何文件")
+		return nil
+	}
+
+	var freed int64
+	failed := 0
+	for _, item := range items {
+		var err error
+		if item.Version != "" {
+			if err = removeVersionDir(item.Path); err == nil {
+				err = unregisterVersionDir(baseDir, cfg, item.Version, item.Path)
+			}
+		} else {
+			err = os.RemoveAll(item.Path)
+		}
+		if err != nil {
+			fmt.Printf("⚠️ 删除失败 %s: %v\n", item.Path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("🗑️  %s (%s) - %s\n", item.Path, formatBytes(item.Size), item.Reason)
+		freed += item.Size
+	}
+
+	fmt.Printf("✅ 清理完成，释放 %s", formatBytes(freed))
+	if failed > 0 {
+		fmt.Printf("，%d 项删除失败", failed)
+	}
+	fmt.Println()
+	return nil
+}
+
+// SetPinned 固定或取消固定版本
+func SetPinned(version string, pinned bool) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if pinned {
+		if err := cfg.Pin(version); err != nil {
+			return err
+		}
+		fmt.Printf("📌 已固定 Go %s，清理时不会被删除\n", version)
+		return nil
+	}
+	if err := cfg.Unpin(version); err != nil {
+		return err
+	}
+	fmt.Printf("✅ 已取消固定 Go %s\n", version)
+	return nil
+}
+
+// pruneLeftovers 中断的安装留下的临时目录和未删除的旧版本目录
+func pruneLeftovers(baseDir string) []pruneItem {
+	var items []pruneItem
+	versionDir := filepath.Join(baseDir, "go-version")
+	entries, err := os.ReadDir(versionDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(versionDir, name)
+		switch {
+		case strings.HasPrefix(name, oldPrefix):
+			items = append(items, pruneItem{Path: path, Reason: "未删除的旧版本目录", Size: dirSize(path)})
+		case strings.HasPrefix(name, stagingPrefix):
+			// 正在进行的安装也会使用临时目录，只清理较早的
+			info, err := entry.Info()
+			if err != nil || time.Since(info.ModTime()) < staleStagingAge {
+				continue
+			}
+			items = append(items, pruneItem{Path: path, Reason: "中断安装的临时目录", Size: dirSize(path)})
+		}
+	}
+	return items
+}
+
+// pruneVersions 每个次版本、架构和系统保留最新的 keep 个补丁版本
+func pruneVersions(baseDir string, cfg *config.Config, keep int) []pruneItem {
+	versionDir := filepath.Join(baseDir, "go-version")
+	entries, err := os.ReadDir(versionDir)
+	if err != nil {
+		return nil
+	}
+
+	type installed struct {
+		version string
+		path    string
+	}
+	groups := make(map[string][]installed)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, arch, goos, ok := parseVersionDirName(entry.Name())
+		if !ok {
+			continue
+		}
+		// 源码编译等非正式版本名称不参与补丁版本清理
+		if _, ok := parseGoVersion(version); !ok {
+			continue
+		}
+		minor := minorPattern.FindString(version)
+		key := minor + "/" + arch + "/" + goos
+		groups[key] = append(groups[key], installed{version, filepath.Join(versionDir, entry.Name())})
+	}
+
+	var items []pruneItem
+	for _, list := range groups {
+		sort.Slice(list, func(i, j int) bool {
+			return compareGoVersions(list[i].version, list[j].version) > 0
+		})
+		for i, v := range list {
+			if i < keep {
+				continue
+			}
+			if isCurrentGoRoot(cfg, v.version, v.path) {
+				fmt.Printf("💡 跳过当前使用的版本: %s\n", v.path)
+				continue
+			}
+			if cfg.IsPinned(v.version) {
+				fmt.Printf("📌 跳过固定的版本: %s\n", v.path)
+				continue
+			}
+			items = append(items, pruneItem{
+				Path:    v.path,
+				Reason:  fmt.Sprintf("每个次版本保留最新 %d 个补丁", keep),
+				Size:    dirSize(v.path),
+				Version: v.version,
+			})
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Path < items[j].Path })
+	return items
+}
+
+// pruneArchives 删除已安装版本的安装包和过期的安装包
+func pruneArchives(baseDir string, opts PruneOptions) []pruneItem {
+	if !opts.InstalledArchives && opts.ArchiveDays <= 0 {
+		return nil
+	}
+
+	installed := make(map[string]bool)
+	if opts.InstalledArchives {
+		if entries, err := os.ReadDir(filepath.Join(baseDir, "go-version")); err == nil {
+			for _, entry := range entries {
+				version, arch, goos, ok := parseVersionDirName(entry.Name())
+				if !ok || !entry.IsDir() {
+					continue
+				}
+				for _, name := range versionArchiveNames(version, goos, arch) {
+					installed[strings.ToLower(name)] = true
+				}
+			}
+		}
+	}
+
+	downloadDir := filepath.Join(baseDir, "down")
+	entries, err := os.ReadDir(downloadDir)
+	if err != nil {
+		return nil
+	}
+
+	var items []pruneItem
+	for _, entry := range entries {
+		if entry.IsDir() || !isArchiveFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(downloadDir, entry.Name())
+		switch {
+		case installed[strings.ToLower(entry.Name())]:
+			items = append(items, pruneItem{Path: path, Reason: "对应版本已安装", Size: info.Size()})
+		case opts.ArchiveDays > 0 && time.Since(info.ModTime()) > time.Duration(opts.ArchiveDays)*24*time.Hour:
+			items = append(items, pruneItem{Path: path, Reason: fmt.Sprintf("超过 %d 天", opts.ArchiveDays), Size: info.Size()})
+		}
+	}
+	return items
+}
+
+// pruneBackups 只保留最新的 keep 个环境变量备份
+func pruneBackups(baseDir string, keep int) []pruneItem {
+	backupDir := filepath.Join(baseDir, "backup_env")
+	entries, err := os.ReadDir(backupDir)
+	if err != nil {
+		return nil
+	}
+
+	var backups []os.DirEntry
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), "env_backup_") && strings.HasSuffix(entry.Name(), ".json") {
+			backups = append(backups, entry)
+		}
+	}
+	// 文件名包含时间戳，按名称倒序即为从新到旧
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name() > backups[j].Name() })
+
+	var items []pruneItem
+	for i, entry := range backups {
+		if i < keep {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		items = append(items, pruneItem{
+			Path:   filepath.Join(backupDir, entry.Name()),
+			Reason: fmt.Sprintf("只保留最新 %d 个备份", keep),
+			Size:   info.Size(),
+		})
+	}
+	return items
+}
+
+// isArchiveFile 判断文件名是否为安装包
+func isArchiveFile(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz")
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"regexp"
 	"strings"
+
+	"go-version-switch/internal/config"
 )
 
 const (
@@ -87,34 +89,57 @@ func parseVersions(html string) ([]*GoRelease, error) {
 			DownloadURL: "https://go.dev" + downloadURL,
 		}
 
-		// 只添加 Windows 的 Archive 版本
-		if release.OS == "Windows" && release.Kind == "Archive" {
+		// 只添加 Windows、Linux 和 macOS 的 Archive 版本
+		isSupportedOS := release.OS == "Windows" || release.OS == "Linux" || release.OS == "macOS"
+		if isSupportedOS && release.Kind == "Archive" {
 			// 标准化架构名称
-			switch {
-			case strings.Contains(strings.ToLower(release.Arch), "386"):
-				release.Arch = "x86"
-			case strings.Contains(strings.ToLower(release.Arch), "amd64"):
-				release.Arch = "amd64"
-			case strings.Contains(strings.ToLower(release.Arch), "arm64"):
-				release.Arch = "arm64"
-			case strings.Contains(strings.ToLower(release.Arch), "arm"):
-				release.Arch = "arm"
-			}
+			release.Arch = normalizeReleaseArch(release.Arch)
 			releases = append(releases, release)
 		}
 	}
 
 	if len(releases) == 0 {
-		return nil, fmt.Errorf("未找到可用的Windows版本")
+		return nil, fmt.Errorf("未找到可用的安装包版本")
 	}
 
-	fmt.Printf("解析到 %d 个Windows版本\n", len(releases))
+	fmt.Printf("解析到 %d 个安装包版本 (Windows/Linux/macOS)\n", len(releases))
 	return releases, nil
 }
 
+// versionsCache 版本列表缓存文件 (data/config/versions.json) 的格式
+type versionsCache struct {
+	SchemaVersion int          `json:"schema_version"`
+	Versions      []*GoRelease `json:"versions"`
+}
+
+// versionsCacheMigrations 版本列表缓存的迁移，下标为迁移前的格式版本
+var versionsCacheMigrations = []config.Migration{
+	// 版本 0 -> 1: 版本 0 是 GoRelease 数组，包装为带格式版本的对象，并标准化架构名称
+	func(doc any) (any, error) {
+		list, ok := doc.([]any)
+		if !ok {
+			return nil, fmt.Errorf("版本缓存不是数组")
+		}
+		for _, item := range list {
+			release, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			if arch, ok := release["Arch"].(string); ok {
+				release["Arch"] = normalizeReleaseArch(arch)
+			}
+		}
+		return map[string]any{"versions": list}, nil
+	},
+}
+
 // SaveVersionsCache 保存版本信息到缓存
 func SaveVersionsCache(releases []*GoRelease, cacheFile string) error {
-	data, err := json.MarshalIndent(releases, "", "    ")
+	cache := versionsCache{
+		SchemaVersion: len(versionsCacheMigrations),
+		Versions:      releases,
+	}
+	data, err := json.MarshalIndent(cache, "", "    ")
 	if err != nil {
 		return fmt.Errorf("序列化版本信息失败: %v", err)
 	}
@@ -122,31 +147,41 @@ func SaveVersionsCache(releases []*GoRelease, cacheFile string) error {
 	return os.WriteFile(cacheFile, data, 0644)
 }
 
-// LoadVersionsCache 从缓存加载版本信息
+// LoadVersionsCache 从缓存加载版本信息，旧格式的缓存升级后写回
 func LoadVersionsCache(cacheFile string) ([]*GoRelease, error) {
 	data, err := os.ReadFile(cacheFile)
 	if err != nil {
 		return nil, err
 	}
 
-	var releases []*GoRelease
-	if err := json.Unmarshal(data, &releases); err != nil {
+	var cache versionsCache
+	migrated, err := config.DecodeMigrated(data, versionsCacheMigrations, &cache)
+	if err != nil {
 		return nil, err
 	}
-
-	// 标准化架构名称
-	for _, release := range releases {
-		switch {
-		case strings.Contains(strings.ToLower(release.Arch), "386"):
-			release.Arch = "x86"
-		case strings.Contains(strings.ToLower(release.Arch), "amd64"):
-			release.Arch = "amd64"
-		case strings.Contains(strings.ToLower(release.Arch), "arm64"):
-			release.Arch = "arm64"
-		case strings.Contains(strings.ToLower(release.Arch), "arm"):
-			release.Arch = "arm"
+	if migrated {
+		// 保持文件修改时间，避免影响缓存过期判断
+		modTime := getFileModTime(cacheFile)
+		if err := SaveVersionsCache(cache.Versions, cacheFile); err != nil {
+			fmt.Printf("警告: 保存升级后的版本缓存失败: %v\n", err)
+		} else {
+			os.Chtimes(cacheFile, modTime, modTime)
 		}
 	}
+	return cache.Versions, nil
+}
 
-	return releases, nil
+// normalizeReleaseArch 标准化官网版本列表中的架构名称
+func normalizeReleaseArch(arch string) string {
+	switch {
+	case strings.Contains(strings.ToLower(arch), "386"):
+		return "x86"
+	case strings.Contains(strings.ToLower(arch), "amd64"):
+		return "amd64"
+	case strings.Contains(strings.ToLower(arch), "arm64"):
+		return "arm64"
+	case strings.Contains(strings.ToLower(arch), "arm"):
+		return "arm"
+	}
+	return arch
 }
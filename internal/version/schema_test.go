@@ -0,0 +1,145 @@
+package version
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-version-switch/internal/config"
+)
+
+// copyFixture 将 testdata 中的历史格式文件复制到临时目录，迁移写回不会修改原文件
+func copyFixture(t *testing.T, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// savedSchemaVersion 读取文件中记录的格式版本
+func savedSchemaVersion(t *testing.T, path string) any {
+	t.Helper()
+	var doc map[string]any
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("写回的文件不是 JSON 对象: %v", err)
+	}
+	return doc[config.SchemaKey]
+}
+
+func TestLoadVersionsCacheFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		arches  []string // 迁移后的架构名称，为空表示应拒绝
+	}{
+		{name: "v0 GoRelease 数组", fixture: "versions_v0.json", arches: []string{"amd64", "x86", "arm"}},
+		{name: "未来格式版本", fixture: "versions_future.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := copyFixture(t, tt.fixture)
+			modTime := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+			if err := os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+			original, _ := os.ReadFile(path)
+
+			releases, err := LoadVersionsCache(path)
+			if tt.arches == nil {
+				if err == nil || !strings.Contains(err.Error(), "格式版本高于") {
+					t.Fatalf("LoadVersionsCache = %v，期望拒绝更高的格式版本", err)
+				}
+				if data, _ := os.ReadFile(path); string(data) != string(original) {
+					t.Error("更高格式版本的缓存被修改")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadVersionsCache: %v", err)
+			}
+
+			if len(releases) != len(tt.arches) {
+				t.Fatalf("len(releases) = %d，期望 %d", len(releases), len(tt.arches))
+			}
+			for i, r := range releases {
+				if r.Version != "1.21.5" || r.OS != "Windows" || r.SHA256 == "" || r.DownloadURL == "" {
+					t.Errorf("releases[%d] = %+v，字段未正确读取", i, r)
+				}
+				if r.Arch != tt.arches[i] {
+					t.Errorf("releases[%d].Arch = %q，期望 %q", i, r.Arch, tt.arches[i])
+				}
+			}
+
+			// 升级后的缓存已写回，且保持修改时间，不影响缓存过期判断
+			if v := savedSchemaVersion(t, path); v != float64(len(versionsCacheMigrations)) {
+				t.Errorf("写回的 schema_version = %v", v)
+			}
+			if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(modTime) {
+				t.Errorf("缓存修改时间被改变: %v", info.ModTime())
+			}
+			again, err := LoadVersionsCache(path)
+			if err != nil || len(again) != len(releases) {
+				t.Errorf("重新读取写回的缓存 = %d, %v", len(again), err)
+			}
+		})
+	}
+}
+
+func TestLoadEnvBackupFixtures(t *testing.T) {
+	tests := []struct {
+		name    string
+		fixture string
+		newer   bool
+	}{
+		{name: "v0 备份", fixture: "env_backup_v0.json"},
+		{name: "未来格式版本", fixture: "env_backup_future.json", newer: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := copyFixture(t, tt.fixture)
+			original, _ := os.ReadFile(path)
+
+			backup, err := loadEnvBackup(path)
+			if tt.newer {
+				if err == nil || !strings.Contains(err.Error(), "格式版本高于") {
+					t.Fatalf("loadEnvBackup = %v，期望拒绝更高的格式版本", err)
+				}
+				if data, _ := os.ReadFile(path); string(data) != string(original) {
+					t.Error("更高格式版本的备份被修改")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadEnvBackup: %v", err)
+			}
+
+			if backup.SchemaVersion != len(envBackupMigrations) {
+				t.Errorf("SchemaVersion = %d，期望 %d", backup.SchemaVersion, len(envBackupMigrations))
+			}
+			if backup.Timestamp != "20240301_102030" || backup.GOROOT != `C:\Program Files\Go` || backup.GOARCH != "amd64" {
+				t.Errorf("备份字段未正确读取: %+v", backup)
+			}
+			if !strings.Contains(backup.Path, "%GOROOT%") {
+				t.Errorf("Path = %q", backup.Path)
+			}
+			if v := savedSchemaVersion(t, path); v != float64(len(envBackupMigrations)) {
+				t.Errorf("写回的 schema_version = %v", v)
+			}
+		})
+	}
+}
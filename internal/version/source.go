@@ -0,0 +1,310 @@
+package version
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"go-version-switch/internal/config"
+)
+
+// SourceBuildOptions 源码构建选项
+type SourceBuildOptions struct {
+	Source    string // 源码目录或源码包 (tar.gz/zip)
+	Name      string // 安装后的版本名称，如 tip
+	Bootstrap string // 自举工具链：已安装的版本号、登记的名称或 GOROOT 路径，为空时自动选择
+	Force     bool   // 允许使用 Go 版本号作为名称，并覆盖同名的已有版本
+}
+
+// sourceNamePattern 版本名称会出现在目录名中，不允许包含 "-" 和路径分隔符
+var sourceNamePattern = regexp.MustCompile(`^[A-Za-z0-9._]+$`)
+
+// InstallFromSource 在临时目录中编译 Go 源码，成功后登记为受管理的版本
+func InstallFromSource(baseDir string, opts SourceBuildOptions) error {
+	if opts.Name == "" {
+		return fmt.Errorf("未指定版本名称，请使用 -name 指定（如 -name tip）")
+	}
+	if !sourceNamePattern.MatchString(opts.Name) {
+		return fmt.Errorf("版本名称只能包含字母、数字、\".\" 和 \"_\": %s", opts.Name)
+	}
+	if err := checkSourceName(baseDir, opts); err != nil {
+		return err
+	}
+
+	source, err := filepath.Abs(opts.Source)
+	if err != nil {
+		return fmt.Errorf("解析源码路径失败: %v", err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("源码路径不存在: %s", source)
+	}
+
+	bootstrap, err := selectBootstrap(baseDir, opts.Bootstrap)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🔧 自举工具链: %s\n", bootstrap)
+
+	arch := normalizeArch(runtime.GOARCH)
+	extractDir := filepath.Join(baseDir, "go-version")
+	if err := os.MkdirAll(extractDir, 0755); err != nil {
+		return fmt.Errorf("创建安装目录失败: %v", err)
+	}
+	targetDir := filepath.Join(extractDir, versionDirName(opts.Name, runtime.GOOS, arch))
+	staging := stagingDirFor(targetDir)
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("清理临时目录失败: %v", err)
+	}
+
+	// 准备源码副本，编译在副本中进行，不修改原始目录
+	if info.IsDir() {
+		fmt.Printf("📂 正在复制源码: %s\n", source)
+		err = copySourceTree(source, staging)
+	} else {
+		fmt.Printf("📦 正在解压源码包: %s\n", source)
+		err = extractSourceArchive(source, staging)
+	}
+	if err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	if err := ensureVersionFile(source, staging, opts.Name); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+
+	fmt.Printf("🔨 正在编译 Go 源码，可能需要几分钟...\n")
+	if err := runMakeScript(staging, bootstrap); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("❌ 编译失败: %v", err)
+	}
+
+	fmt.Println("🔍 正在验证编译结果...")
+	if err := validateStagedGoRoot(staging, runtime.GOOS, arch); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("❌ 编译结果验证失败: %v", err)
+	}
+	// 源码编译的版本没有安装包，清单只能用于校验，无法自动修复
+	m, err := newInstallManifest(staging, targetDir, "", "")
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	if err := commitStagedInstall(staging, targetDir); err != nil {
+		return err
+	}
+	saveInstallManifest(targetDir, m)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if err := cfg.AddVersion(opts.Name, targetDir); err != nil {
+		return fmt.Errorf("保存版本信息失败: %v", err)
+	}
+
+	fmt.Printf("✅ 已从源码构建 %s\n", opts.Name)
+	fmt.Printf("📂 安装目录: %s\n", targetDir)
+	fmt.Printf("💡 使用 'go-version-switch -use %s' 切换到该版本\n", opts.Name)
+	return nil
+}
+
+// checkSourceName 拒绝与官方版本混淆或会替换已有版本的名称，指定 Force 时允许
+func checkSourceName(baseDir string, opts SourceBuildOptions) error {
+	if opts.Force {
+		return nil
+	}
+	if _, ok := parseGoVersion(opts.Name); ok {
+		return fmt.Errorf("名称 %s 是 Go 版本号，会与官方版本混淆，请使用其他名称（如 tip），确需使用时加 -force", opts.Name)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if path, ok := cfg.Versions[opts.Name]; ok {
+		return fmt.Errorf("版本 %s 已存在 (%s)，使用 -force 覆盖", opts.Name, path)
+	}
+	targetDir := filepath.Join(baseDir, "go-version", versionDirName(opts.Name, runtime.GOOS, normalizeArch(runtime.GOARCH)))
+	if _, err := os.Stat(targetDir); err == nil {
+		return fmt.Errorf("目录 %s 已存在，使用 -force 覆盖", targetDir)
+	}
+	return nil
+}
+
+// selectBootstrap 选择自举工具链，未指定时使用已安装的最新正式版本
+func selectBootstrap(baseDir, bootstrap string) (string, error) {
+	if bootstrap != "" {
+		// 通过 -link 登记的名称
+		if cfg, err := config.LoadConfig(); err == nil {
+			if path, ok := cfg.Versions[bootstrap]; ok && !isManagedPath(baseDir, path) {
+				bootstrap = path
+			}
+		}
+		if info, err := os.Stat(bootstrap); err == nil && info.IsDir() {
+			if err := validateGoRootPath(bootstrap); err != nil {
+				return "", fmt.Errorf("无效的自举工具链: %v", err)
+			}
+			return filepath.Abs(bootstrap)
+		}
+	}
+
+	versions, err := GetInstalledVersions(baseDir)
+	if err != nil {
+		return "", err
+	}
+
+	var best *GoVersion
+	for _, v := range versions {
+		if bootstrap != "" && v.Version != bootstrap {
+			continue
+		}
+		if _, ok := parseGoVersion(v.Version); !ok && bootstrap == "" {
+			continue
+		}
+		goos, goarch, err := detectBinaryPlatform(goBinaryPath(v.Path, runtime.GOOS))
+		if err != nil || !canRunToolchain(goos, goarch) {
+			continue
+		}
+		if best == nil || compareGoVersions(v.Version, best.Version) > 0 {
+			best = v
+		}
+	}
+
+	if best == nil {
+		if bootstrap != "" {
+			return "", fmt.Errorf("未找到可在本机运行的自举工具链: %s", bootstrap)
+		}
+		return "", fmt.Errorf("未找到可用的自举工具链，请先安装一个 Go 版本或使用 -bootstrap 指定 GOROOT")
+	}
+	return best.Path, nil
+}
+
+// copySourceTree 复制源码目录，跳过 .git
+func copySourceTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && rel == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case info.Mode().IsRegular():
+			if err := copyFile(path, target); err != nil {
+				return fmt.Errorf("复制文件失败 %s: %v", rel, err)
+			}
+			return os.Chmod(target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// extractSourceArchive 解压源码包，自动识别顶层目录（go/ 或 go-master/ 等）
+func extractSourceArchive(archive, staging string) error {
+	tmp := staging + ".src"
+	if err := os.RemoveAll(tmp); err != nil {
+		return fmt.Errorf("清理临时目录失败: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractArchive(archive, tmp, ""); err != nil {
+		return fmt.Errorf("解压源码包失败: %v", err)
+	}
+
+	root := tmp
+	if !isGoSourceTree(root) {
+		entries, err := os.ReadDir(tmp)
+		if err != nil {
+			return err
+		}
+		if len(entries) != 1 || !entries[0].IsDir() || !isGoSourceTree(filepath.Join(tmp, entries[0].Name())) {
+			return fmt.Errorf("源码包中未找到 src/make.bash，不是有效的 Go 源码")
+		}
+		root = filepath.Join(tmp, entries[0].Name())
+	}
+	return os.Rename(root, staging)
+}
+
+// isGoSourceTree 判断目录是否为 Go 源码根目录
+func isGoSourceTree(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "src", "make.bash"))
+	return err == nil
+}
+
+// ensureVersionFile 源码中没有 VERSION 文件时写入一个，dist 在没有 .git 时依赖该文件
+func ensureVersionFile(source, goRoot, name string) error {
+	if !isGoSourceTree(goRoot) {
+		return fmt.Errorf("未找到 src/make.bash，不是有效的 Go 源码: %s", source)
+	}
+	versionFile := filepath.Join(goRoot, "VERSION")
+	if _, err := os.Stat(versionFile); err == nil {
+		return nil
+	}
+
+	// 源码是 git 仓库时记录当前提交
+	version := fmt.Sprintf("devel %s %s", name, time.Now().Format(time.UnixDate))
+	if _, err := os.Stat(filepath.Join(source, ".git")); err == nil {
+		out, err := exec.Command("git", "-C", source, "log", "-1", "--format=%h %cd").Output()
+		if fields := strings.SplitN(strings.TrimSpace(string(out)), " ", 2); err == nil && len(fields) == 2 {
+			version = fmt.Sprintf("devel %s-%s %s", name, fields[0], fields[1])
+		}
+	}
+
+	if err := os.WriteFile(versionFile, []byte(version+"\n"), 0644); err != nil {
+		return fmt.Errorf("写入 VERSION 文件失败: %v", err)
+	}
+	fmt.Printf("📝 已生成 VERSION: %s\n", version)
+	return nil
+}
+
+// runMakeScript 在 src 目录下执行 make.bat 或 make.bash
+func runMakeScript(goRoot, bootstrap string) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", "make.bat")
+	} else {
+		cmd = exec.Command("bash", "make.bash")
+	}
+	cmd.Dir = filepath.Join(goRoot, "src")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// 清除可能影响编译的环境变量
+	for _, env := range os.Environ() {
+		key := strings.ToUpper(strings.SplitN(env, "=", 2)[0])
+		switch key {
+		case "GOROOT", "GOOS", "GOARCH", "GOBIN", "GOFLAGS", "GOTOOLCHAIN", "GOROOT_BOOTSTRAP":
+			continue
+		}
+		cmd.Env = append(cmd.Env, env)
+	}
+	cmd.Env = append(cmd.Env, "GOROOT_BOOTSTRAP="+bootstrap, "GOTOOLCHAIN=local")
+
+	return cmd.Run()
+}
@@ -0,0 +1,51 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"go-version-switch/internal/config"
+)
+
+func TestCheckSourceName(t *testing.T) {
+	baseDir := t.TempDir()
+	old := config.DataDir()
+	config.SetDataDir(baseDir)
+	t.Cleanup(func() { config.SetDataDir(old) })
+
+	// 已安装的 tip（目录）和登记的 corp（配置）
+	tipDir := filepath.Join(baseDir, "go-version", versionDirName("tip", runtime.GOOS, normalizeArch(runtime.GOARCH)))
+	if err := os.MkdirAll(tipDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.AddVersion("corp", filepath.Join(baseDir, "corp")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		force bool
+		ok    bool
+	}{
+		{name: "dev", ok: true},
+		{name: "1.22.0"},
+		{name: "go1.23rc1"},
+		{name: "1.22"},
+		{name: "tip"},
+		{name: "corp"},
+		{name: "1.22.0", force: true, ok: true},
+		{name: "tip", force: true, ok: true},
+	}
+	for _, tt := range tests {
+		err := checkSourceName(baseDir, SourceBuildOptions{Name: tt.name, Force: tt.force})
+		if (err == nil) != tt.ok {
+			t.Errorf("checkSourceName(%q, force=%v) = %v，期望通过: %v", tt.name, tt.force, err, tt.ok)
+		}
+	}
+}
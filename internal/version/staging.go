@@ -0,0 +1,194 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// 临时目录前缀，版本扫描时会被忽略
+const (
+	stagingPrefix = ".staging-"
+	oldPrefix     = ".old-"
+)
+
+const probeTimeout = 30 * time.Second
+
+// installFromArchive 先解压到同级临时目录，校验通过后再替换目标目录，
+// 解压或校验失败时保留原有版本不变
+func installFromArchive(archivePath, prefix, targetDir, goos, arch string) error {
+	staging := stagingDirFor(targetDir)
+	if err := os.RemoveAll(staging); err != nil {
+		return fmt.Errorf("清理临时目录失败: %v", err)
+	}
+
+	if err := extractArchive(archivePath, staging, prefix); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("解压失败: %v", err)
+	}
+	if err := fixToolchainPermissions(staging, goos); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("设置可执行权限失败: %v", err)
+	}
+
+	fmt.Println("🔍 正在验证解压结果...")
+	if err := validateStagedGoRoot(staging, goos, arch); err != nil {
+		os.RemoveAll(staging)
+		return fmt.Errorf("❌ 安装包内容验证失败: %v", err)
+	}
+
+	// 清单在临时目录中生成，替换成功后才保存，替换失败时原版本保留原来的清单
+	m, err := newInstallManifest(staging, targetDir, archivePath, prefix)
+	if err != nil {
+		fmt.Printf("⚠️ %v\n", err)
+	}
+	if err := commitStagedInstall(staging, targetDir); err != nil {
+		return err
+	}
+	saveInstallManifest(targetDir, m)
+	return nil
+}
+
+// stagingDirFor 返回目标目录对应的同级临时目录
+func stagingDirFor(targetDir string) string {
+	return filepath.Join(filepath.Dir(targetDir),
+		fmt.Sprintf("%s%s-%d", stagingPrefix, filepath.Base(targetDir), os.Getpid()))
+}
+
+// checkGoRootLayout 检查安装目录是否包含 bin/go、pkg 和 src，goos 为安装目录的目标系统
+func checkGoRootLayout(dir, goos string) error {
+	for _, path := range []string{goBinaryPath(dir, goos), filepath.Join(dir, "pkg"), filepath.Join(dir, "src")} {
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("无效的Go安装目录，缺少必要文件: %s", path)
+		}
+	}
+	return nil
+}
+
+// validateStagedGoRoot 检查临时目录的完整性，本机可运行时执行 bin/go version
+func validateStagedGoRoot(dir, goos, arch string) error {
+	if err := checkGoRootLayout(dir, goos); err != nil {
+		return err
+	}
+
+	if !canRunToolchain(goos, arch) {
+		fmt.Printf("💡 目标平台 %s/%s 无法在本机运行，跳过 go version 检测\n", goos, goarchOf(arch))
+		return nil
+	}
+
+	output, err := probeGoVersion(dir, goos)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ %s\n", output)
+	return nil
+}
+
+// probeGoVersion 运行指定目录下的 go version，返回其输出
+func probeGoVersion(goRoot, goos string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, goBinaryPath(goRoot, goos), "version")
+	cmd.Dir = goRoot
+	cmd.Env = append(os.Environ(), "GOROOT="+goRoot, "GOTOOLCHAIN=local")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("执行 go version 失败: %v\n%s", err, output)
+	}
+
+	result := strings.TrimSpace(string(output))
+	if !strings.HasPrefix(result, "go version ") {
+		return "", fmt.Errorf("go version 输出异常: %s", result)
+	}
+	return result, nil
+}
+
+// commitStagedInstall 用临时目录替换目标目录，替换成功后才删除旧版本，失败时删除临时目录
+func commitStagedInstall(staging, targetDir string) error {
+	if err := swapStagedInstall(staging, targetDir); err != nil {
+		os.RemoveAll(staging)
+		return err
+	}
+	return nil
+}
+
+// swapStagedInstall 用临时目录替换目标目录，失败时恢复原目录并保留临时目录
+func swapStagedInstall(staging, targetDir string) error {
+	var oldDir string
+	if _, err := os.Stat(targetDir); err == nil {
+		fmt.Printf("🗑️  检测到已存在的目录: %s\n", targetDir)
+		oldDir = filepath.Join(filepath.Dir(targetDir),
+			fmt.Sprintf("%s%s-%d", oldPrefix, filepath.Base(targetDir), time.Now().Unix()))
+		if err := os.Rename(targetDir, oldDir); err != nil {
+			return fmt.Errorf("替换目录失败，请确保没有程序（终端、编辑器、正在运行的 Go 程序）正在使用 %s: %v", targetDir, err)
+		}
+	}
+
+	if err := os.Rename(staging, targetDir); err != nil {
+		if oldDir != "" {
+			if rerr := os.Rename(oldDir, targetDir); rerr != nil {
+				fmt.Printf("⚠️ 恢复原目录失败，原版本保留在: %s\n", oldDir)
+			}
+		}
+		return fmt.Errorf("移动安装目录失败: %v", err)
+	}
+
+	if oldDir != "" {
+		if err := os.RemoveAll(oldDir); err != nil {
+			fmt.Printf("⚠️ 清理旧版本目录失败，可稍后手动删除: %s (%v)\n", oldDir, err)
+		}
+	}
+	return nil
+}
+
+// fixToolchainPermissions 为 bin 和 pkg/tool 下的程序补充可执行权限，
+// 模块代理提供的工具链 zip 不保留文件权限
+func fixToolchainPermissions(goRoot, goos string) error {
+	if goos == "windows" || runtime.GOOS == "windows" {
+		return nil
+	}
+	patterns := []string{
+		filepath.Join(goRoot, "bin", "*"),
+		filepath.Join(goRoot, "pkg", "tool", "*", "*"),
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		for _, path := range matches {
+			info, err := os.Lstat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if err := os.Chmod(path, info.Mode().Perm()|0111); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// goBinaryPath 返回指定系统下 go 可执行文件的路径
+func goBinaryPath(goRoot, goos string) string {
+	name := "go"
+	if goos == "windows" {
+		name += exeSuffix
+	}
+	return filepath.Join(goRoot, "bin", name)
+}
+
+// canRunToolchain 判断目标平台的工具链能否在本机运行
+func canRunToolchain(goos, arch string) bool {
+	if goos != runtime.GOOS {
+		return false
+	}
+	goarch := goarchOf(arch)
+	return goarch == runtime.GOARCH || (runtime.GOARCH == "amd64" && goarch == "386")
+}
@@ -0,0 +1,190 @@
+package version
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// toolchainModule Go 1.21 起工具链以模块形式发布在模块代理上
+const toolchainModule = "golang.org/toolchain"
+
+// defaultModuleProxy GOPROXY 未设置时使用的官方代理
+const defaultModuleProxy = "https://proxy.golang.org"
+
+// toolchainModuleVersion 生成工具链模块版本，如 v0.0.1-go1.21.5.windows-amd64
+func toolchainModuleVersion(version, goos, arch string) string {
+	return fmt.Sprintf("v0.0.1-go%s.%s-%s", version, goos, goarchOf(arch))
+}
+
+// supportsToolchainModule 只有 1.21 及以上版本发布了工具链模块
+func supportsToolchainModule(version string) bool {
+	m := regexp.MustCompile(`^1\.(\d+)`).FindStringSubmatch(version)
+	if m == nil {
+		return false
+	}
+	minor, _ := strconv.Atoi(m[1])
+	return minor >= 21
+}
+
+// resolveProxyURL 解析模块代理地址，"env" 表示使用 GOPROXY 中第一个可用代理
+func resolveProxyURL(proxy string) (string, error) {
+	if proxy == "env" {
+		proxy = ""
+		for _, p := range strings.FieldsFunc(os.Getenv("GOPROXY"), func(r rune) bool { return r == ',' || r == '|' }) {
+			if p = strings.TrimSpace(p); p != "" && p != "direct" && p != "off" {
+				proxy = p
+				break
+			}
+		}
+		if proxy == "" {
+			proxy = defaultModuleProxy
+		}
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "file") {
+		return "", fmt.Errorf("无效的模块代理地址: %s", proxy)
+	}
+	return strings.TrimSuffix(proxy, "/"), nil
+}
+
+// installFromProxy 通过 GOPROXY 协议下载工具链模块并安装
+func installFromProxy(baseDir string, opts InstallOptions) error {
+	if !supportsToolchainModule(opts.Version) {
+		return fmt.Errorf("模块代理仅提供 Go 1.21 及以上版本的工具链: %s", opts.Version)
+	}
+
+	proxy, err := resolveProxyURL(opts.Proxy)
+	if err != nil {
+		return err
+	}
+
+	modVersion := toolchainModuleVersion(opts.Version, opts.OS, opts.Arch)
+	modPath := toolchainModule + "@" + modVersion
+	fmt.Printf("🌐 模块代理: %s\n", proxy)
+	fmt.Printf("📦 工具链模块: %s\n", modPath)
+
+	// 期望的模块哈希：命令行指定或从代理转发的校验和数据库查询（校验签名）
+	expected := opts.H1
+	if expected == "" {
+		expected, err = lookupModuleHash(baseDir, proxy, modVersion)
+		if err != nil {
+			if !opts.SkipVerify {
+				return fmt.Errorf("❌ 获取模块校验和失败，拒绝安装（可使用 -h1 指定校验和，或确认来源可信时使用 -skip-verify）: %v", err)
+			}
+			fmt.Printf("⚠️ 获取模块校验和失败，已按要求跳过校验: %v\n", err)
+		}
+	}
+
+	downloadDir := filepath.Join(baseDir, "down")
+	if err := os.MkdirAll(downloadDir, 0755); err != nil {
+		return fmt.Errorf("📁 创建下载目录失败: %v", err)
+	}
+	zipPath := filepath.Join(downloadDir, "toolchain-"+modVersion+".zip")
+
+	// 已下载且校验通过时直接使用
+	reuse := false
+	if _, err := os.Stat(zipPath); err == nil && expected != "" {
+		if verifyModuleZip(zipPath, expected) == nil {
+			fmt.Printf("💡 发现已下载的文件: %s\n", zipPath)
+			reuse = true
+		}
+	}
+
+	if !reuse {
+		zipURL := fmt.Sprintf("%s/%s/@v/%s.zip", proxy, toolchainModule, modVersion)
+		fmt.Printf("📥 开始下载: %s\n", zipURL)
+		if err := fetchProxyFile(zipURL, zipPath); err != nil {
+			os.Remove(zipPath)
+			return fmt.Errorf("❌ 下载失败: %v", err)
+		}
+
+		if expected != "" {
+			fmt.Printf("🔍 正在验证模块哈希...\n")
+			if err := verifyModuleZip(zipPath, expected); err != nil {
+				os.Remove(zipPath)
+				return fmt.Errorf("❌ %v", err)
+			}
+			fmt.Printf("✅ 模块哈希验证成功\n")
+		}
+	}
+
+	// 模块 zip 中的文件位于 golang.org/toolchain@<版本>/ 下
+	installDir, err := extractGo(baseDir, zipPath, modPath+"/", opts.Version, opts.OS, opts.Arch)
+	if err != nil {
+		return fmt.Errorf("解压工具链模块失败: %v", err)
+	}
+
+	fmt.Printf("✅ Go %s (%s) 安装完成\n", opts.Version, opts.Arch)
+	fmt.Printf("📂 安装目录: %s\n", installDir)
+	return nil
+}
+
+// verifyModuleZip 计算模块 zip 的 h1 哈希（与 go.sum 和校验和数据库相同的算法）并比较
+func verifyModuleZip(zipPath, expected string) error {
+	actual, err := dirhash.HashZip(zipPath, dirhash.Hash1)
+	if err != nil {
+		return fmt.Errorf("计算模块哈希失败: %v", err)
+	}
+	if actual != expected {
+		return fmt.Errorf("模块哈希不匹配\n期望: %s\n实际: %s", expected, actual)
+	}
+	return nil
+}
+
+// fetchProxyFile 从代理下载文件，支持 http(s) 与 file:// 代理
+func fetchProxyFile(rawURL, destPath string) error {
+	if strings.HasPrefix(rawURL, "file://") {
+		src, err := fileURLPath(rawURL)
+		if err != nil {
+			return err
+		}
+		return copyFile(src, destPath)
+	}
+	return downloadWithProgress(rawURL, destPath)
+}
+
+// readProxyFile 读取代理上的小文件
+func readProxyFile(rawURL string) ([]byte, error) {
+	if strings.HasPrefix(rawURL, "file://") {
+		src, err := fileURLPath(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(src)
+	}
+
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("服务器返回: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// fileURLPath 将 file:// 地址转换为本地路径
+func fileURLPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无效的文件地址: %s", rawURL)
+	}
+	p := u.Path
+	// file:///C:/proxy 在 Windows 上解析为 /C:/proxy
+	if runtime.GOOS == "windows" && len(p) > 2 && p[0] == '/' && p[2] == ':' {
+		p = p[1:]
+	}
+	return filepath.FromSlash(p), nil
+}
@@ -0,0 +1,238 @@
+package version
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+)
+
+const fixtureVersion = "1.21.5"
+
+// fixtureOS 选择与本机不同的系统，安装时只检查文件结构，不执行 go version
+func fixtureOS() string {
+	if runtime.GOOS == "linux" {
+		return "windows"
+	}
+	return "linux"
+}
+
+// writeToolchainZip 生成最小的 golang.org/toolchain 模块 zip
+func writeToolchainZip(t *testing.T, path, modVersion, goos string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	prefix := toolchainModule + "@" + modVersion + "/"
+	files := map[string]string{
+		"VERSION":               "go" + fixtureVersion + "\ntime 2023-11-29T21:21:52Z\n",
+		goBinaryName(goos):      "fake go binary",
+		"pkg/tool/README":       "tools",
+		"src/runtime/extern.go": "package runtime\n",
+		"src/cmd/go/alldocs.go": "package main\n",
+	}
+	zw := zip.NewWriter(out)
+	for name, content := range files {
+		w, err := zw.Create(prefix + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goBinaryName 返回 bin 目录下 go 可执行文件的相对路径
+func goBinaryName(goos string) string {
+	if goos == "windows" {
+		return "bin/go.exe"
+	}
+	return "bin/go"
+}
+
+// writeSumdbFixture 用 signer 签名一个只包含 gosum 记录的校验和数据库，
+// 按代理的目录结构写入 lookup 结果和数据块
+func writeSumdbFixture(t *testing.T, proxyDir, signer, modVersion, gosum string) {
+	t.Helper()
+
+	name := strings.Split(strings.TrimPrefix(signer, "PRIVATE+KEY+"), "+")[0]
+	ops := sumdb.NewTestServer(signer, func(path, vers string) ([]byte, error) {
+		return []byte(gosum), nil
+	})
+	srv := sumdb.NewServer(ops)
+	dbDir := filepath.Join(proxyDir, "sumdb", name)
+
+	lookup := "/lookup/" + toolchainModule + "@" + modVersion
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, lookup, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("生成 lookup 失败: %d %s", rec.Code, rec.Body)
+	}
+	writeFixtureFile(t, filepath.Join(dbDir, filepath.FromSlash(lookup)), rec.Body.Bytes())
+
+	for _, tile := range tlog.NewTiles(8, 0, 1) {
+		data, err := ops.ReadTileData(context.Background(), tile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writeFixtureFile(t, filepath.Join(dbDir, filepath.FromSlash(tile.Path())), data)
+	}
+}
+
+func writeFixtureFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// proxyFixture 文件形式的模块代理
+type proxyFixture struct {
+	baseDir    string
+	zipPath    string
+	proxyURL   string
+	modVersion string
+	goos       string
+	h1         string
+}
+
+// newProxyFixture 生成模块 zip 和由 signer 签名的校验和数据库，
+// 并让客户端信任 trusted 对应的公钥
+func newProxyFixture(t *testing.T, signer, trusted string) *proxyFixture {
+	t.Helper()
+
+	dir := t.TempDir()
+	goos := fixtureOS()
+	modVersion := toolchainModuleVersion(fixtureVersion, goos, "amd64")
+	proxyDir := filepath.Join(dir, "proxy")
+
+	zipPath := filepath.Join(proxyDir, toolchainModule, "@v", modVersion+".zip")
+	writeToolchainZip(t, zipPath, modVersion, goos)
+	h1, err := dirhash.HashZip(zipPath, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gosum := fmt.Sprintf("%s %s %s\n%s %s/go.mod h1:MiRyMdhHl5mDr2glx4YEY6fqjGZNDn3Ys5VbT1LbHnE=\n",
+		toolchainModule, modVersion, h1, toolchainModule, modVersion)
+	writeSumdbFixture(t, proxyDir, signer, modVersion, gosum)
+
+	old := sumdbVerifierKey
+	sumdbVerifierKey = trusted
+	t.Cleanup(func() { sumdbVerifierKey = old })
+
+	// Windows 上的地址形如 file:///C:/proxy
+	proxyURL := "file://" + filepath.ToSlash(proxyDir)
+	if runtime.GOOS == "windows" {
+		proxyURL = "file:///" + filepath.ToSlash(proxyDir)
+	}
+
+	return &proxyFixture{
+		baseDir:    filepath.Join(dir, "data"),
+		zipPath:    zipPath,
+		proxyURL:   proxyURL,
+		modVersion: modVersion,
+		goos:       goos,
+		h1:         h1,
+	}
+}
+
+func (f *proxyFixture) install() error {
+	return installFromProxy(f.baseDir, InstallOptions{
+		Version:  fixtureVersion,
+		OS:       f.goos,
+		Arch:     "amd64",
+		Proxy:    f.proxyURL,
+		NoPrompt: true,
+	})
+}
+
+func generateSumdbKey(t *testing.T, name string) (string, string) {
+	t.Helper()
+	skey, vkey, err := note.GenerateKey(rand.Reader, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return skey, vkey
+}
+
+func TestInstallFromFileProxy(t *testing.T) {
+	skey, vkey := generateSumdbKey(t, "sum.test")
+	f := newProxyFixture(t, skey, vkey)
+
+	if err := verifyModuleZip(f.zipPath, f.h1); err != nil {
+		t.Fatalf("verifyModuleZip: %v", err)
+	}
+
+	hash, err := lookupModuleHash(f.baseDir, f.proxyURL, f.modVersion)
+	if err != nil {
+		t.Fatalf("lookupModuleHash: %v", err)
+	}
+	if hash != f.h1 {
+		t.Fatalf("lookupModuleHash = %q，期望 %q", hash, f.h1)
+	}
+
+	if err := f.install(); err != nil {
+		t.Fatalf("installFromProxy: %v", err)
+	}
+	goRoot := filepath.Join(f.baseDir, "go-version", versionDirName(fixtureVersion, f.goos, "amd64"))
+	if _, err := os.Stat(filepath.Join(goRoot, filepath.FromSlash(goBinaryName(f.goos)))); err != nil {
+		t.Fatalf("安装后缺少 go 可执行文件: %v", err)
+	}
+}
+
+func TestInstallFromFileProxyRejectsUntrustedSumdb(t *testing.T) {
+	// 代理用自己的密钥签名校验和数据库，客户端只信任另一个密钥
+	skey, _ := generateSumdbKey(t, "sum.test")
+	_, trusted := generateSumdbKey(t, "sum.test")
+	f := newProxyFixture(t, skey, trusted)
+
+	if _, err := lookupModuleHash(f.baseDir, f.proxyURL, f.modVersion); err == nil {
+		t.Fatal("签名无效的校验和数据库应被拒绝")
+	}
+	if err := f.install(); err == nil {
+		t.Fatal("无法验证校验和时应拒绝安装")
+	}
+}
+
+func TestInstallFromFileProxyRejectsModifiedZip(t *testing.T) {
+	skey, vkey := generateSumdbKey(t, "sum.test")
+	f := newProxyFixture(t, skey, vkey)
+
+	// 校验和数据库记录的是原始 zip，替换为其他系统的内容后哈希不一致
+	other := "darwin"
+	if f.goos == other {
+		other = "linux"
+	}
+	writeToolchainZip(t, f.zipPath, f.modVersion, other)
+
+	if err := f.install(); err == nil || !strings.Contains(err.Error(), "模块哈希不匹配") {
+		t.Fatalf("installFromProxy = %v，期望哈希不匹配", err)
+	}
+}
@@ -0,0 +1,180 @@
+package version
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"go-version-switch/internal/config"
+)
+
+// majorVersionSuffix 匹配模块路径末尾的主版本后缀，如 /v2
+var majorVersionSuffix = regexp.MustCompile(`^v\d+$`)
+
+// toolsDirFor 返回版本专属的 GOBIN 目录 data/tools/<版本目录名>
+// 通过 -link 登记的外部目录使用路径哈希区分，避免同名目录冲突
+func toolsDirFor(baseDir, goRoot string) string {
+	name := filepath.Base(goRoot)
+	if !isManagedPath(baseDir, goRoot) {
+		sum := sha256.Sum256([]byte(strings.ToLower(filepath.Clean(goRoot))))
+		name = fmt.Sprintf("ext-%s-%x", name, sum[:4])
+	}
+	return filepath.Join(baseDir, "tools", name)
+}
+
+// sharedBinDir 当前版本工具的统一目录，需加入 PATH
+func sharedBinDir(baseDir string) string {
+	return filepath.Join(baseDir, "bin")
+}
+
+// toolBinaryName 根据包路径推断 go install 生成的可执行文件名
+func toolBinaryName(pkg string) string {
+	pkg = strings.SplitN(pkg, "@", 2)[0]
+	parts := strings.Split(strings.TrimSuffix(pkg, "/"), "/")
+	name := parts[len(parts)-1]
+	if majorVersionSuffix.MatchString(name) && len(parts) > 1 {
+		name = parts[len(parts)-2]
+	}
+	if runtime.GOOS == "windows" {
+		name += exeSuffix
+	}
+	return name
+}
+
+// InstallTools 使用指定版本的 go install 安装配置中的工具到版本专属目录
+// 单个工具安装失败只输出警告，不影响 Go 版本本身的安装
+func InstallTools(baseDir, goRoot string, force bool) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if len(cfg.Tools) == 0 {
+		return nil
+	}
+
+	goos, goarch, err := detectBinaryPlatform(goBinaryPath(goRoot, runtime.GOOS))
+	if err != nil || !canRunToolchain(goos, goarch) {
+		fmt.Printf("💡 %s 无法在本机运行，跳过工具安装\n", goRoot)
+		return nil
+	}
+
+	toolsDir := toolsDirFor(baseDir, goRoot)
+	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+		return fmt.Errorf("创建工具目录失败: %v", err)
+	}
+
+	var failed []string
+	for _, pkg := range cfg.Tools {
+		if !strings.Contains(pkg, "@") {
+			pkg += "@latest"
+		}
+		if !force {
+			if _, err := os.Stat(filepath.Join(toolsDir, toolBinaryName(pkg))); err == nil {
+				continue
+			}
+		}
+
+		fmt.Printf("🔧 正在安装工具: %s\n", pkg)
+		cmd := exec.Command(goBinaryPath(goRoot, runtime.GOOS), "install", pkg)
+		cmd.Env = append(os.Environ(), "GOROOT="+goRoot, "GOBIN="+toolsDir, "GOTOOLCHAIN=local")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			fmt.Printf("⚠️ 安装 %s 失败: %v\n%s", pkg, err, output)
+			failed = append(failed, pkg)
+			continue
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Printf("⚠️ %d 个工具安装失败: %s\n", len(failed), strings.Join(failed, ", "))
+	} else {
+		fmt.Printf("✅ 工具已安装到: %s\n", toolsDir)
+	}
+	return nil
+}
+
+// installVersionTools 安装完成后为新版本安装工具
+func installVersionTools(baseDir string, opts InstallOptions) error {
+	versionDir := filepath.Join(baseDir, "go-version",
+		versionDirName(opts.Version, normalizeOS(opts.OS), normalizeArch(opts.Arch)))
+	if err := InstallTools(baseDir, versionDir, true); err != nil {
+		fmt.Printf("⚠️ 安装工具失败: %v\n", err)
+	}
+	return nil
+}
+
+// LinkTools 将版本专属目录中的工具链接到统一的 data/bin，切换版本时调用，
+// 只链接已安装的工具，缺失的工具由调用方在切换前安装
+func LinkTools(baseDir, goRoot string) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	// 移除上一个版本的工具，正在运行的程序（如编辑器中的 gopls）可能无法删除
+	binDir := sharedBinDir(baseDir)
+	if old, err := os.ReadDir(binDir); err == nil {
+		for _, entry := range old {
+			if err := os.Remove(filepath.Join(binDir, entry.Name())); err != nil {
+				fmt.Printf("⚠️ 无法替换 %s，请关闭正在使用它的程序后重新执行 -use: %v\n", entry.Name(), err)
+			}
+		}
+	}
+	if len(cfg.Tools) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return fmt.Errorf("创建工具目录失败: %v", err)
+	}
+
+	toolsDir := toolsDirFor(baseDir, goRoot)
+	entries, err := os.ReadDir(toolsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取工具目录失败: %v", err)
+	}
+
+	linked := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		src := filepath.Join(toolsDir, entry.Name())
+		dst := filepath.Join(binDir, entry.Name())
+		if _, err := os.Lstat(dst); err == nil {
+			continue
+		}
+		// 优先使用硬链接，跨卷等情况下退回复制
+		if err := os.Link(src, dst); err != nil {
+			if err := copyFile(src, dst); err != nil {
+				return fmt.Errorf("链接工具 %s 失败: %v", entry.Name(), err)
+			}
+			if info, err := entry.Info(); err == nil {
+				os.Chmod(dst, info.Mode().Perm())
+			}
+		}
+		linked++
+	}
+
+	fmt.Printf("✅ 已链接 %d 个工具到: %s\n", linked, binDir)
+	if !pathContains(binDir) {
+		fmt.Printf("💡 请将 %s 加入 PATH 以使用这些工具\n", binDir)
+	}
+	return nil
+}
+
+// pathContains 判断目录是否已在 PATH 中
+func pathContains(dir string) bool {
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if p != "" && strings.EqualFold(filepath.Clean(p), filepath.Clean(dir)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-version-switch/internal/config"
+)
+
+func TestLinkToolsClearsBinWithoutTools(t *testing.T) {
+	baseDir := t.TempDir()
+	old := config.DataDir()
+	config.SetDataDir(baseDir)
+	t.Cleanup(func() { config.SetDataDir(old) })
+
+	// 上一个版本链接的工具
+	binDir := sharedBinDir(baseDir)
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(binDir, toolBinaryName("golang.org/x/tools/gopls")), nil, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	goRoot := filepath.Join(baseDir, "go-version", "go-1.21.5-amd64")
+	if err := LinkTools(baseDir, goRoot); err != nil {
+		t.Fatalf("LinkTools: %v", err)
+	}
+	if entries, _ := os.ReadDir(binDir); len(entries) != 0 {
+		t.Fatalf("未配置工具时 data/bin 应为空，实际有 %d 个文件", len(entries))
+	}
+}
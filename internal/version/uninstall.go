@@ -0,0 +1,183 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"go-version-switch/internal/config"
+)
+
+// UninstallOptions 卸载选项
+type UninstallOptions struct {
+	Version string // 版本号或 -link 登记的名称
+	OS      string // 目标操作系统，为空时使用当前系统
+	Arch    string // 架构，为空时使用当前系统架构
+	Purge   bool   // 同时删除 data/down 和 data/cache 中的安装包
+}
+
+// UninstallVersion 卸载受管理的版本；通过 -link 登记的外部目录只取消登记，不删除文件
+func UninstallVersion(baseDir string, opts UninstallOptions) error {
+	if opts.Arch == "" {
+		opts.Arch = runtime.GOARCH
+	}
+	arch := normalizeArch(opts.Arch)
+	if arch == "" {
+		return fmt.Errorf("不支持的架构: %s", opts.Arch)
+	}
+	goos := normalizeOS(opts.OS)
+	if goos == "" {
+		return fmt.Errorf("不支持的操作系统: %s (可选: windows/linux/darwin)", opts.OS)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	versionDir := filepath.Join(baseDir, "go-version", versionDirName(opts.Version, goos, arch))
+	managed := true
+	if _, err := os.Stat(versionDir); os.IsNotExist(err) {
+		linked, ok := cfg.Versions[opts.Version]
+		if ok && isManagedPath(baseDir, linked) {
+			if _, err := os.Stat(linked); os.IsNotExist(err) {
+				// 目录已被手动删除，只清理配置中残留的记录
+				if err := unregisterVersionDir(baseDir, cfg, opts.Version, linked); err != nil {
+					return err
+				}
+				fmt.Printf("🧹 目录 %s 已不存在，已从配置中移除 Go %s\n", linked, opts.Version)
+				return nil
+			}
+		}
+		if !ok || isManagedPath(baseDir, linked) {
+			return fmt.Errorf("版本 %s (%s) 未安装", opts.Version, arch)
+		}
+		versionDir = linked
+		managed = false
+	}
+
+	// 正在使用的版本需要确认
+	if isCurrentGoRoot(cfg, opts.Version, versionDir) {
+		fmt.Printf("⚠️ Go %s 是当前正在使用的版本，卸载后需要切换到其他版本\n", opts.Version)
+		fmt.Print("❓ 确定要卸载吗? [y/N] ")
+		var answer string
+		fmt.Scanln(&answer)
+		if strings.ToLower(answer) != "y" {
+			return fmt.Errorf("已取消卸载")
+		}
+	}
+
+	if managed {
+		fmt.Printf("🗑️  正在删除: %s\n", versionDir)
+		if err := removeVersionDir(versionDir); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("💡 %s 是通过 -link 登记的目录，只取消登记，不删除文件\n", versionDir)
+	}
+
+	if managed && opts.Purge {
+		purgeArchives(baseDir, versionDir, opts.Version, goos, arch)
+	}
+
+	if err := unregisterVersionDir(baseDir, cfg, opts.Version, versionDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ 已卸载 Go %s (%s)\n", opts.Version, arch)
+	return nil
+}
+
+// unregisterVersionDir 删除版本的工具目录并从配置中移除
+// 配置中的版本只记录一个路径，指向其他架构时保留
+func unregisterVersionDir(baseDir string, cfg *config.Config, version, dir string) error {
+	if err := os.RemoveAll(toolsDirFor(baseDir, dir)); err != nil {
+		fmt.Printf("⚠️ 删除工具目录失败: %v\n", err)
+	}
+	if isManagedPath(baseDir, dir) {
+		removeManifest(dir)
+	}
+
+	if path, ok := cfg.Versions[version]; ok && samePath(path, dir) {
+		if cfg.CurrentVersion == version {
+			cfg.CurrentVersion = ""
+		}
+		if err := cfg.RemoveVersion(version); err != nil {
+			return fmt.Errorf("更新配置失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// isCurrentGoRoot 判断目录是否为当前使用的版本
+func isCurrentGoRoot(cfg *config.Config, version, dir string) bool {
+	if cfg.CurrentVersion == version && samePath(cfg.Versions[version], dir) {
+		return true
+	}
+	goroot := os.Getenv("GOROOT")
+	return goroot != "" && samePath(goroot, dir)
+}
+
+// removeVersionDir 先重命名再删除，删除中断时不会留下看似完整的版本目录
+func removeVersionDir(dir string) error {
+	oldDir := filepath.Join(filepath.Dir(dir),
+		fmt.Sprintf("%s%s-%d", oldPrefix, filepath.Base(dir), time.Now().Unix()))
+	if err := os.Rename(dir, oldDir); err != nil {
+		return fmt.Errorf("删除目录失败，请确保没有程序（终端、编辑器、正在运行的 Go 程序）正在使用 %s: %v", dir, err)
+	}
+	if err := os.RemoveAll(oldDir); err != nil {
+		fmt.Printf("⚠️ 部分文件删除失败，可稍后手动删除: %s (%v)\n", oldDir, err)
+	}
+	return nil
+}
+
+// purgeArchives 删除 data/down 中该版本的安装包，以及本地缓存 data/cache 中安装时使用的安装包
+// （其他目录的共享缓存可能被其他用户使用，不删除）
+func purgeArchives(baseDir, versionDir, version, goos, arch string) {
+	cache := openArchiveCache(baseDir)
+	if m, err := loadManifest(manifestPathFor(versionDir)); err == nil && len(m.ArchiveSHA256) == 64 &&
+		samePath(cache.Dir, filepath.Join(baseDir, "cache")) {
+		cached := cache.path(m.ArchiveSHA256)
+		if _, err := os.Stat(cached); err == nil {
+			cache.evict(m.ArchiveSHA256)
+			fmt.Printf("🗑️  已删除缓存的安装包: %s\n", cached)
+		}
+	}
+
+	downloadDir := filepath.Join(baseDir, "down")
+	for _, name := range versionArchiveNames(version, goos, arch) {
+		path := filepath.Join(downloadDir, name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			fmt.Printf("⚠️ 删除安装包失败: %v\n", err)
+			continue
+		}
+		fmt.Printf("🗑️  已删除安装包: %s\n", path)
+	}
+}
+
+// versionArchiveNames 返回 data/down 中某个版本可能对应的安装包文件名
+func versionArchiveNames(version, goos, arch string) []string {
+	names := []string{archiveFileName(version, goos, arch)}
+	if supportsToolchainModule(version) {
+		names = append(names, "toolchain-"+toolchainModuleVersion(version, goos, arch)+".zip")
+	}
+	return names
+}
+
+// samePath 比较两个路径是否相同，Windows 下不区分大小写
+func samePath(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
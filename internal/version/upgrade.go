@@ -0,0 +1,211 @@
+package version
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"go-version-switch/internal/config"
+)
+
+// UpgradeOptions 升级选项
+type UpgradeOptions struct {
+	Minor     string // 次版本，如 1.21，为空时使用当前版本的次版本
+	OS        string // 目标操作系统，为空时使用当前系统
+	Arch      string // 架构，为空时使用当前系统架构
+	RemoveOld bool   // 升级后删除被替代的补丁版本
+}
+
+// UpgradeVersion 安装次版本的最新补丁版本，原版本正在使用时自动切换，
+// 并继承固定状态；工具按配置为新版本重新安装
+func UpgradeVersion(baseDir string, opts UpgradeOptions) error {
+	if opts.Arch == "" {
+		opts.Arch = runtime.GOARCH
+	}
+	arch := normalizeArch(opts.Arch)
+	if arch == "" {
+		return fmt.Errorf("不支持的架构: %s", opts.Arch)
+	}
+	goos := normalizeOS(opts.OS)
+	if goos == "" {
+		return fmt.Errorf("不支持的操作系统: %s (可选: windows/linux/darwin)", opts.OS)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+
+	minor := opts.Minor
+	if minor == "" {
+		minor = cfg.CurrentVersion
+		if minor == "" {
+			return fmt.Errorf("没有正在使用的版本，请指定次版本，如: -upgrade 1.21")
+		}
+	}
+	if _, ok := parseGoVersion(minor); !ok {
+		return fmt.Errorf("无效的版本号: %s", minor)
+	}
+	minor = minorPattern.FindString(minor)
+
+	installed, _ := latestInstalledPatch(baseDir, minor, goos, arch)
+	oldVersion, oldDir := replacedPatch(baseDir, cfg, minor, goos, arch)
+
+	latest, err := latestReleasePatch(baseDir, minor, goos, arch)
+	if err != nil {
+		return err
+	}
+	if oldVersion != "" && compareGoVersions(latest, oldVersion) <= 0 {
+		fmt.Printf("✅ Go %s 已是 %s 的最新补丁版本\n", oldVersion, minor)
+		return nil
+	}
+
+	wasCurrent := oldVersion != "" && isCurrentGoRoot(cfg, oldVersion, oldDir)
+	wasPinned := oldVersion != "" && cfg.IsPinned(oldVersion)
+	if oldVersion != "" {
+		fmt.Printf("⬆️  升级 Go %s -> %s (%s/%s)\n", oldVersion, latest, goos, arch)
+	} else {
+		fmt.Printf("⬆️  未安装 %s 的任何补丁版本，将安装最新的 Go %s (%s/%s)\n", minor, latest, goos, arch)
+	}
+
+	// 是否切换由原版本是否正在使用决定，不询问
+	if installed == latest {
+		fmt.Printf("💡 Go %s 已安装，跳过安装\n", latest)
+	} else if err := InstallVersion(baseDir, InstallOptions{Version: latest, OS: goos, Arch: arch, NoPrompt: true}); err != nil {
+		return err
+	}
+	if oldVersion == "" {
+		return nil
+	}
+
+	if wasCurrent {
+		fmt.Printf("🔄 Go %s 是当前使用的版本，切换到 Go %s\n", oldVersion, latest)
+		if err := UseVersion(baseDir, InstallOptions{Version: latest, OS: goos, Arch: arch}); err != nil {
+			return fmt.Errorf("切换到新版本失败，原版本已保留: %v", err)
+		}
+	}
+
+	// 安装和切换过程中配置已被修改，重新加载
+	cfg, err = config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %v", err)
+	}
+	if wasPinned && !cfg.IsPinned(latest) {
+		if err := cfg.Pin(latest); err != nil {
+			return fmt.Errorf("固定新版本失败: %v", err)
+		}
+		fmt.Printf("📌 Go %s 是固定的版本，已同时固定 Go %s\n", oldVersion, latest)
+	}
+
+	if opts.RemoveOld {
+		switch {
+		case wasPinned:
+			fmt.Printf("📌 Go %s 是固定的版本，不会被删除，可先使用 -unpin 取消固定\n", oldVersion)
+		case !wasCurrent && isCurrentGoRoot(cfg, oldVersion, oldDir):
+			fmt.Printf("💡 Go %s 仍在使用中，未删除\n", oldVersion)
+		default:
+			fmt.Printf("🗑️  正在删除旧版本: %s\n", oldDir)
+			if err := removeVersionDir(oldDir); err != nil {
+				return err
+			}
+			if err := unregisterVersionDir(baseDir, cfg, oldVersion, oldDir); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("✅ 已升级到 Go %s\n", latest)
+	return nil
+}
+
+// latestInstalledPatch 返回已安装的某个次版本的最新正式版本及其目录
+func latestInstalledPatch(baseDir, minor, goos, arch string) (string, string) {
+	versionDir := filepath.Join(baseDir, "go-version")
+	entries, err := os.ReadDir(versionDir)
+	if err != nil {
+		return "", ""
+	}
+
+	var latest, latestDir string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, a, g, ok := parseVersionDirName(entry.Name())
+		if !ok || a != arch || g != goos || !isPatchOf(version, minor) {
+			continue
+		}
+		if latest == "" || compareGoVersions(version, latest) > 0 {
+			latest, latestDir = version, filepath.Join(versionDir, entry.Name())
+		}
+	}
+	return latest, latestDir
+}
+
+// replacedPatch 返回升级时被替代的版本：当前使用的版本属于该次版本时以它为准，
+// 否则为已安装的最新补丁版本
+func replacedPatch(baseDir string, cfg *config.Config, minor, goos, arch string) (string, string) {
+	if version, dir := currentInstalledPatch(baseDir, cfg, minor, goos, arch); version != "" {
+		return version, dir
+	}
+	return latestInstalledPatch(baseDir, minor, goos, arch)
+}
+
+// currentInstalledPatch 当前使用的版本（配置中的当前版本或 GOROOT）属于该次版本时，
+// 返回其版本号和目录
+func currentInstalledPatch(baseDir string, cfg *config.Config, minor, goos, arch string) (string, string) {
+	var candidates []string
+	if cfg.CurrentVersion != "" && cfg.Versions[cfg.CurrentVersion] != "" {
+		candidates = append(candidates, cfg.Versions[cfg.CurrentVersion])
+	}
+	if goroot := os.Getenv("GOROOT"); goroot != "" {
+		candidates = append(candidates, goroot)
+	}
+
+	for _, dir := range candidates {
+		if !isManagedPath(baseDir, dir) {
+			continue
+		}
+		version, a, g, ok := parseVersionDirName(filepath.Base(dir))
+		if !ok || a != arch || g != goos || !isPatchOf(version, minor) {
+			continue
+		}
+		if _, err := os.Stat(dir); err == nil {
+			return version, filepath.Clean(dir)
+		}
+	}
+	return "", ""
+}
+
+// latestReleasePatch 从最新的版本列表中查找次版本的最新正式版本，获取失败时使用缓存
+func latestReleasePatch(baseDir, minor, goos, arch string) (string, error) {
+	list, err := GetVersionList(baseDir, true)
+	if err != nil {
+		fmt.Printf("警告: %v，使用本地缓存的版本列表\n", err)
+		if list, err = GetVersionList(baseDir, false); err != nil {
+			return "", err
+		}
+	}
+
+	var latest string
+	for _, v := range list.Versions {
+		if normalizeOS(v.OS) != goos || !strings.EqualFold(v.Arch, arch) || !isPatchOf(v.Version, minor) {
+			continue
+		}
+		if latest == "" || compareGoVersions(v.Version, latest) > 0 {
+			latest = v.Version
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("版本列表中没有 Go %s 的 %s/%s 正式版本", minor, goos, arch)
+	}
+	return latest, nil
+}
+
+// isPatchOf 判断版本是否为次版本的正式版本（不含 beta/rc）
+func isPatchOf(version, minor string) bool {
+	parts, ok := parseGoVersion(version)
+	return ok && parts[3] == 2 && minorPattern.FindString(version) == minor
+}
@@ -0,0 +1,53 @@
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-version-switch/internal/config"
+)
+
+func TestUpgradeUsesCurrentPatch(t *testing.T) {
+	baseDir := t.TempDir()
+	t.Setenv("GOROOT", "")
+
+	dirs := make(map[string]string)
+	for _, v := range []string{"1.21.3", "1.21.4", "1.22.0"} {
+		dirs[v] = filepath.Join(baseDir, "go-version", versionDirName(v, "windows", "amd64"))
+		if err := os.MkdirAll(dirs[v], 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		version string // 期望被替代的版本
+	}{
+		// 当前使用 1.21.3，即使已安装更新的 1.21.4 也应从 1.21.3 升级
+		{"当前版本属于该次版本", "1.21.3", "1.21.3"},
+		{"当前版本属于其他次版本", "1.22.0", "1.21.4"},
+		{"没有当前版本", "", "1.21.4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{CurrentVersion: tt.current, Versions: make(map[string]string)}
+			for v, dir := range dirs {
+				cfg.Versions[v] = dir
+			}
+
+			version, dir := replacedPatch(baseDir, cfg, "1.21", "windows", "amd64")
+			if version != tt.version || dir != dirs[tt.version] {
+				t.Fatalf("被替代的版本 = %s (%s)，期望 %s", version, dir, tt.version)
+			}
+		})
+	}
+
+	// 通过 GOROOT 识别当前版本
+	t.Setenv("GOROOT", dirs["1.21.3"])
+	cfg := &config.Config{Versions: make(map[string]string)}
+	if version, _ := replacedPatch(baseDir, cfg, "1.21", "windows", "amd64"); version != "1.21.3" {
+		t.Fatalf("replacedPatch = %q，期望从 GOROOT 识别 1.21.3", version)
+	}
+}
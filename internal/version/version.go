@@ -184,8 +184,88 @@ func normalizeArch(arch string) string {
 
 }
 
+// goarchOf 将标准化的架构名称转换为 GOARCH
+func goarchOf(arch string) string {
+	switch normalizeArch(arch) {
+	case "x86":
+		return "386"
+	case "amd64":
+		return "amd64"
+	case "ARM":
+		return "arm"
+	case "ARM64":
+		return "arm64"
+	default:
+		return strings.ToLower(arch)
+	}
+}
+
+// normalizeOS 标准化操作系统名称，为空时使用当前系统
+func normalizeOS(goos string) string {
+	switch strings.ToLower(strings.TrimSpace(goos)) {
+	case "":
+		return runtime.GOOS
+	case "windows", "win":
+		return "windows"
+	case "linux":
+		return "linux"
+	case "darwin", "macos", "mac", "osx":
+		return "darwin"
+	default:
+		return ""
+	}
+}
+
+// goVersionPattern 匹配 1.21、1.21.5、1.22rc1、1.21beta2 等版本号
+var goVersionPattern = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?(?:(beta|rc)(\d+))?$`)
+
+// parseGoVersion 将版本号解析为可比较的数值，预发布版本排在正式版之前
+func parseGoVersion(version string) ([5]int, bool) {
+	var parts [5]int
+	m := goVersionPattern.FindStringSubmatch(strings.TrimPrefix(version, "go"))
+	if m == nil {
+		return parts, false
+	}
+	parts[0], _ = strconv.Atoi(m[1])
+	parts[1], _ = strconv.Atoi(m[2])
+	parts[2], _ = strconv.Atoi(m[3])
+	switch m[4] {
+	case "beta":
+		parts[3] = 0
+	case "rc":
+		parts[3] = 1
+	default:
+		parts[3] = 2
+	}
+	parts[4], _ = strconv.Atoi(m[5])
+	return parts, true
+}
+
+// compareGoVersions 按数值比较两个版本号，无法解析的版本视为最小
+func compareGoVersions(v1, v2 string) int {
+	p1, ok1 := parseGoVersion(v1)
+	p2, ok2 := parseGoVersion(v2)
+	switch {
+	case !ok1 && !ok2:
+		return strings.Compare(v1, v2)
+	case !ok1:
+		return -1
+	case !ok2:
+		return 1
+	}
+	for i := range p1 {
+		if p1[i] != p2[i] {
+			if p1[i] > p2[i] {
+				return 1
+			}
+			return -1
+		}
+	}
+	return 0
+}
+
 // checkDownloadDirectory 检查下载目录中的安装包
-func checkDownloadDirectory(baseDir, targetArch string) error {
+func checkDownloadDirectory(baseDir, targetArch string, skipVerify bool) error {
 	downDir := filepath.Join(baseDir, "down")
 	if _, err := os.Stat(downDir); err == nil {
 		entries, err := os.ReadDir(downDir)
@@ -196,8 +276,9 @@ func checkDownloadDirectory(baseDir, targetArch string) error {
 					continue
 				}
 				name := strings.ToLower(entry.Name())
-				// 检查是否是zip文件且包含目标架构
-				if strings.HasSuffix(name, ".zip") && strings.Contains(name, strings.ToLower(targetArch)) {
+				// 检查是否是安装包且包含目标架构
+				isArchive := strings.HasSuffix(name, ".zip") || strings.HasSuffix(name, ".tar.gz")
+				if isArchive && strings.Contains(name, strings.ToLower(targetArch)) {
 					zipFiles = append(zipFiles, entry.Name())
 				}
 			}
@@ -235,10 +316,18 @@ func checkDownloadDirectory(baseDir, targetArch string) error {
 					return fmt.Errorf("❌ 无法从文件名解析版本号: %s", selectedZip)
 				}
 
+				// 从文件名中提取目标系统，如 go1.21.5.linux-amd64.tar.gz
+				var goos string
+				if osMatch := regexp.MustCompile(`go[\d.]+\.(\w+)-`).FindStringSubmatch(selectedZip); len(osMatch) >= 2 {
+					goos = normalizeOS(osMatch[1])
+				}
+
 				opts := InstallOptions{
-					Version: versionMatch[1],
-					Arch:    targetArch,
-					ZipPath: zipPath, // 使用本地zip文件
+					Version:    versionMatch[1],
+					OS:         goos,
+					Arch:       targetArch,
+					ZipPath:    zipPath, // 使用本地zip文件
+					SkipVerify: skipVerify,
 				}
 
 				if err := InstallVersion(baseDir, opts); err != nil {
@@ -252,7 +341,7 @@ func checkDownloadDirectory(baseDir, targetArch string) error {
 }
 
 // HandleArchitectureSwitch 处理架构切换
-func HandleArchitectureSwitch(baseDir string, archFlag string) error {
+func HandleArchitectureSwitch(baseDir string, archFlag string, skipVerify bool) error {
 	fmt.Println("\n🔍 开始搜索架构目录...")
 
 	// 标准化用户输入的架构名称
@@ -333,7 +422,7 @@ func HandleArchitectureSwitch(baseDir string, archFlag string) error {
 		}
 
 		// 检查下载目录
-		if err := checkDownloadDirectory(baseDir, targetArch); err != nil {
+		if err := checkDownloadDirectory(baseDir, targetArch, skipVerify); err != nil {
 			if len(invalidDirs) > 0 {
 				return fmt.Errorf("❌ 存在不完整的目录且未找到有效的安装包，请手动修复或重新安装")
 			}
@@ -380,12 +469,17 @@ func HandleArchitectureSwitch(baseDir string, archFlag string) error {
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			fmt.Printf("❌ 目录不完整，缺少必要文件: %s\n", file)
 			fmt.Println("🔄 尝试从下载目录安装...")
-			return checkDownloadDirectory(baseDir, targetArch)
+			return checkDownloadDirectory(baseDir, targetArch, skipVerify)
 		}
 	}
 	fmt.Println("✅ 目录完整性验证通过")
 
-	if err := SetAsCurrentGo(goRoot); err != nil {
+	// 记录当前版本并切换工具，旧格式的目录名无法解析时读取 VERSION 文件
+	version, _, _, ok := parseVersionDirName(selectedDir)
+	if !ok {
+		version, _ = detectGoRootVersion(goRoot)
+	}
+	if err := activateGoRoot(baseDir, version, goRoot); err != nil {
 		return fmt.Errorf("❌ 设置Go环境失败: %v", err)
 	}
 